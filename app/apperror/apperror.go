@@ -0,0 +1,193 @@
+package apperror
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"task-vix-btpns/app/i18n"
+)
+
+//Code tells client what kind of error happened, it doesn't change when message is reworded
+type Code string
+
+const (
+	CodeValidation      Code = "VALIDATION"        //request is malformed or invalid
+	CodeBadRequest      Code = "BAD_REQUEST"       //request body can't be parsed at all
+	CodeUnauthorized    Code = "UNAUTHORIZED"      //credential or token is missing or wrong
+	CodeForbidden       Code = "FORBIDDEN"         //user is known but not allowed
+	CodeNotFound        Code = "NOT_FOUND"         //requested resource doesn't exist
+	CodeConflict        Code = "CONFLICT"          //resource already exists
+	CodePayloadTooLarge Code = "PAYLOAD_TOO_LARGE" //request body exceeds the limit
+	CodeRateLimited     Code = "RATE_LIMITED"      //too many requests, client should retry later
+	CodeInternal        Code = "INTERNAL"          //unexpected failure, details are only logged
+)
+
+//HTTP status of each code
+var statuses = map[Code]int{
+	CodeValidation:      http.StatusUnprocessableEntity,
+	CodeBadRequest:      http.StatusBadRequest,
+	CodeUnauthorized:    http.StatusUnauthorized,
+	CodeForbidden:       http.StatusForbidden,
+	CodeNotFound:        http.StatusNotFound,
+	CodeConflict:        http.StatusConflict,
+	CodePayloadTooLarge: http.StatusRequestEntityTooLarge,
+	CodeRateLimited:     http.StatusTooManyRequests,
+	CodeInternal:        http.StatusInternalServerError,
+}
+
+//Invalid value of one request field, code names the failed rule such as required or email
+type FieldError struct {
+	Field   string      `json:"field"`
+	Code    string      `json:"code"`
+	Message string      `json:"message"` //catalog key until the error is localized
+	Params  i18n.Params `json:"-"`       //values of message placeholders besides {field}
+}
+
+//Error returned by handlers, written to client by error middleware
+type Error struct {
+	Code    Code
+	Message string      //catalog key, see package i18n
+	Params  i18n.Params //values of message placeholders, shared by field messages
+	Fields  []FieldError
+	Err     error //cause, never shown to client
+
+	RetryAfter time.Duration //sent as Retry-After header when not zero
+}
+
+func (e *Error) Error() string {
+	message, _ := e.Localize(i18n.LangEN)
+	if e.Err != nil {
+		return message + ": " + e.Err.Error()
+	}
+	return message
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+//Function to set value of message placeholder
+func (e *Error) With(name string, value string) *Error {
+	if e.Params == nil {
+		e.Params = i18n.Params{}
+	}
+	e.Params[name] = value
+	return e
+}
+
+//Function to translate message and field messages into lang
+func (e *Error) Localize(lang string) (string, []FieldError) {
+	fields := make([]FieldError, len(e.Fields))
+	for i, field := range e.Fields {
+		params := i18n.Params{"field": i18n.Label(lang, field.Field)}
+		for name, value := range e.Params {
+			params[name] = value
+		}
+		for name, value := range field.Params {
+			params[name] = value
+		}
+		fields[i] = field
+		fields[i].Message = i18n.T(lang, field.Message, params)
+	}
+
+	//Error made of field errors is described by its first field
+	if len(fields) > 0 && e.Message == e.Fields[0].Message {
+		return fields[0].Message, fields
+	}
+	return i18n.T(lang, e.Message, e.Params), fields
+}
+
+//Function to get HTTP status of error
+func (e *Error) Status() int {
+	if status, ok := statuses[e.Code]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+//Function to create error with code and message
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+//Function to create error with code and message caused by err
+func Wrap(code Code, message string, err error) *Error {
+	return &Error{Code: code, Message: message, Err: err}
+}
+
+//Function to create validation error, fields tell which request fields are invalid
+func Validation(message string, fields ...FieldError) *Error {
+	return &Error{Code: CodeValidation, Message: message, Fields: fields}
+}
+
+//Function to join validation errors into one which reports every failing field, nil errors are skipped.
+//Field already reported by earlier error is left out of later ones, any other error is returned as it is.
+func JoinValidation(errs ...error) error {
+	var joined *Error
+	reported := map[string]bool{}
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		var appErr *Error
+		if !errors.As(err, &appErr) || appErr.Code != CodeValidation {
+			return err
+		}
+		if joined == nil {
+			joined = Validation(appErr.Message)
+		}
+		added := map[string]bool{}
+		for _, field := range appErr.Fields {
+			if !reported[field.Field] {
+				joined.Fields = append(joined.Fields, field)
+				added[field.Field] = true
+			}
+		}
+		for field := range added {
+			reported[field] = true
+		}
+	}
+	if joined == nil {
+		return nil
+	}
+	return joined
+}
+
+//Function to create validation error of a single field
+func Field(field string, code string, message string) *Error {
+	return Validation(message, FieldError{Field: field, Code: code, Message: message})
+}
+
+func NotFound(message string) *Error {
+	return New(CodeNotFound, message)
+}
+
+func Unauthorized(message string) *Error {
+	return New(CodeUnauthorized, message)
+}
+
+func Forbidden(message string) *Error {
+	return New(CodeForbidden, message)
+}
+
+func Conflict(message string, fields ...FieldError) *Error {
+	return &Error{Code: CodeConflict, Message: message, Fields: fields}
+}
+
+//Function to create error telling client to wait before trying again
+func RateLimited(message string, retryAfter time.Duration) *Error {
+	return &Error{Code: CodeRateLimited, Message: message, RetryAfter: retryAfter}
+}
+
+//Function to create internal error, message shown to client is generic
+func Internal(err error) *Error {
+	return Wrap(CodeInternal, "error.internal", err)
+}
+
+//Function to get application error from err, any other error is internal
+func As(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return Internal(err)
+}
@@ -1,45 +1,105 @@
 package auth
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
-	"time"
 	"os"
+	"strings"
+	"time"
+
 	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
 )
 
-var jwtKey = []byte(os.Getenv("API_SECRET"))
+const (
+	defaultIssuer   = "task-vix-btpns" //used when JWT_ISSUER is not set
+	defaultAudience = "task-vix-btpns" //used when JWT_AUDIENCE is not set
+)
+
+const (
+	AccessTokenTTL  = 1 * time.Hour       //lifetime of access token
+	RefreshTokenTTL = 30 * 24 * time.Hour //lifetime of refresh token
+	ResetTokenTTL   = 1 * time.Hour       //lifetime of password reset token
+	VerifyTokenTTL  = 24 * time.Hour      //lifetime of email verification link
+	ChallengeTTL    = 5 * time.Minute     //time to enter two-factor code after password is accepted
+)
 
 type ClaimJWT struct {
 	Username string `json:"username"`
-	Email    string `json:"email"`
+	Role     string `json:"role"`
 	jwt.StandardClaims
 }
 
-//Function to generate JWT token
-func GenerateJWT(email string, username string) (tokenString string, err error) {
-	expiredTime := time.Now().Add(1 * time.Hour) //initialize expiration time
-	claims := &ClaimJWT{                            //initialize claims
-		Email:    email,
+//Function to get token issuer
+func issuer() string {
+	if iss := os.Getenv("JWT_ISSUER"); iss != "" {
+		return iss
+	}
+	return defaultIssuer
+}
+
+//Function to get token audience
+func audience() string {
+	if aud := os.Getenv("JWT_AUDIENCE"); aud != "" {
+		return aud
+	}
+	return defaultAudience
+}
+
+//Function to generate JWT token, subject is the user id
+func GenerateJWT(userID string, username string, role string) (tokenString string, err error) {
+	now := time.Now()
+	expiredTime := now.Add(AccessTokenTTL) //initialize expiration time
+	claims := &ClaimJWT{                   //initialize claims
 		Username: username,
+		Role:     role,
 		StandardClaims: jwt.StandardClaims{
+			Id:        uuid.New().String(), //unique token id, used for revocation
+			Subject:   userID,
+			Issuer:    issuer(),
+			Audience:  audience(),
+			IssuedAt:  now.Unix(),
 			ExpiresAt: expiredTime.Unix(),
 		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims) //initialize token
-	tokenString, err = token.SignedString(jwtKey)              //generate token string
-	return
+	return sign(claims)
 }
 
-//Function to validate JWT token
-func ValidateToken(signedToken string) (err error) {
+//Function to sign claims with active key, kid header tells which key verifies the token
+func sign(claims jwt.Claims) (string, error) {
+	set, err := keys() //get active signing key
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(set.Active.Method, claims) //initialize token
+	if set.Active.ID != "" {
+		token.Header["kid"] = set.Active.ID
+	}
+	return token.SignedString(set.Active.Private) //generate token string
+}
+
+//ErrTokenExpired is returned by ParseToken for token past its expiry time
+var ErrTokenExpired = errors.New("Token has expired")
+
+//Function to parse JWT token and return its claims
+func ParseToken(signedToken string) (claims *ClaimJWT, err error) {
+	set, err := keys()
+	if err != nil {
+		return
+	}
 	token, err := jwt.ParseWithClaims( //parse token
 		signedToken, //token string
 		&ClaimJWT{},
-		func(token *jwt.Token) (interface{}, error) { //validate token
-			return []byte(jwtKey), nil //return error if token is invalid
-		},
+		set.verificationKey, //find key by kid, return error if token is invalid
 	)
-	if err != nil {
+	var validation_err *jwt.ValidationError
+	if errors.As(err, &validation_err) && validation_err.Errors&jwt.ValidationErrorExpired != 0 {
+		err = ErrTokenExpired
+		return
+	} else if err != nil {
 		return
 	}
 	claims, ok := token.Claims.(*ClaimJWT) //get claims
@@ -48,33 +108,74 @@ func ValidateToken(signedToken string) (err error) {
 		return
 	}
 	if claims.ExpiresAt < time.Now().Local().Unix() { //return error if token is expired
-		err = errors.New("Token has expired")
+		err = ErrTokenExpired
+		return
+	}
+	if !claims.VerifyIssuer(issuer(), true) || !claims.VerifyAudience(audience(), true) {
+		err = errors.New("Token is not issued for this service") //return error if issuer or audience mismatch
+		return
+	}
+	if claims.Subject == "" {
+		err = errors.New("Token has no subject")
 		return
 	}
 	return
 }
 
-//Function to take email data from user based on JWT token
-func GetEmail(signedToken string) (email string, err error) {
-	token, err := jwt.ParseWithClaims( //parse token
-		signedToken,
-		&ClaimJWT{},
-		func(token *jwt.Token) (interface{}, error) {
-			return []byte(jwtKey), nil
-		},
-	)
+//Claims of token whose issuer and audience are checked
+type audienceClaims interface {
+	jwt.Claims
+	VerifyIssuer(iss string, required bool) bool
+	VerifyAudience(aud string, required bool) bool
+}
+
+//Function to parse token signed for audience into claims, used by tokens which aren't access tokens
+func parseAudience(signedToken string, claims audienceClaims, aud string) error {
+	set, err := keys()
 	if err != nil {
-		return
+		return err
 	}
-	claims, ok := token.Claims.(*ClaimJWT) //get claims
-	if !ok {
-		err = errors.New("Couldn't parse claims token")
-		return
+	_, err = jwt.ParseWithClaims(signedToken, claims, set.verificationKey)
+	var validation_err *jwt.ValidationError
+	if errors.As(err, &validation_err) && validation_err.Errors&jwt.ValidationErrorExpired != 0 {
+		return ErrTokenExpired
+	} else if err != nil {
+		return err
 	}
-	if claims.ExpiresAt < time.Now().Local().Unix() {
-		err = errors.New("Token has expired")
+	if !claims.VerifyIssuer(issuer(), true) || !claims.VerifyAudience(aud, true) {
+		return errors.New("Token is not issued for " + aud)
+	}
+	return nil
+}
+
+//Function to validate JWT token
+func ValidateToken(signedToken string) (err error) {
+	_, err = ParseToken(signedToken)
+	return
+}
+
+//Function to take bearer token from Authorization header
+func BearerToken(header string) (string, error) {
+	const prefix = "Bearer "
+	if !strings.HasPrefix(header, prefix) || len(header) == len(prefix) {
+		return "", errors.New("Token not found")
+	}
+	return strings.TrimPrefix(header, prefix), nil
+}
+
+//Function to generate random refresh or one-time token, returns the token and its hash
+func GenerateToken() (token string, hashed string, err error) {
+	bytes := make([]byte, 32)
+	if _, err = rand.Read(bytes); err != nil {
 		return
 	}
+	token = base64.RawURLEncoding.EncodeToString(bytes)
+	hashed = HashToken(token)
+	return
+}
 
-	return claims.Email, nil //return email
+//Function to hash refresh or one-time token, only the hash is stored in database
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
@@ -0,0 +1,245 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+//Key used to sign or verify JWT token
+type SigningKey struct {
+	ID      string            //kid header of token
+	Method  jwt.SigningMethod //algorithm used by the key
+	Private interface{}       //nil for verification only key
+	Public  interface{}
+}
+
+//Active signing key and every key accepted for verification
+type KeySet struct {
+	Active *SigningKey
+	Keys   map[string]*SigningKey
+}
+
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+var (
+	keySet  *KeySet
+	keyErr  error
+	keyOnce sync.Once
+)
+
+//Function to load keys from environment, must be called after .env is loaded
+//
+//JWT_PRIVATE_KEY_FILE is the PEM file of the active signing key (RSA or Ed25519).
+//JWT_PUBLIC_KEY_FILES is a comma separated list of PEM files still accepted for verification,
+//used to keep tokens valid while keys are rotated. API_SECRET keeps HS256 tokens without kid valid.
+func LoadKeys() error {
+	keyOnce.Do(func() {
+		keySet, keyErr = loadKeySet(os.Getenv("JWT_PRIVATE_KEY_FILE"), os.Getenv("JWT_PUBLIC_KEY_FILES"), os.Getenv("API_SECRET"))
+	})
+	return keyErr
+}
+
+//Function to get loaded keys
+func keys() (*KeySet, error) {
+	if err := LoadKeys(); err != nil {
+		return nil, err
+	}
+	return keySet, nil
+}
+
+//Function to build key set from PEM files and shared secret
+func loadKeySet(privateFile string, publicFiles string, secret string) (*KeySet, error) {
+	set := &KeySet{Keys: map[string]*SigningKey{}}
+
+	if privateFile != "" {
+		key, err := readKeyFile(privateFile)
+		if err != nil {
+			return nil, err
+		}
+		if key.Private == nil {
+			return nil, fmt.Errorf("%s doesn't contain a private key", privateFile)
+		}
+		set.Active = key
+		set.Keys[key.ID] = key
+	}
+
+	for _, file := range strings.Split(publicFiles, ",") {
+		file = strings.TrimSpace(file)
+		if file == "" {
+			continue
+		}
+		key, err := readKeyFile(file)
+		if err != nil {
+			return nil, err
+		}
+		key.Private = nil //rotated key is only used for verification
+		if _, ok := set.Keys[key.ID]; !ok {
+			set.Keys[key.ID] = key
+		}
+	}
+
+	//Shared secret key, used for tokens without kid
+	if secret != "" {
+		key := &SigningKey{ID: "", Method: jwt.SigningMethodHS256, Private: []byte(secret), Public: []byte(secret)}
+		set.Keys[key.ID] = key
+		if set.Active == nil {
+			set.Active = key
+		}
+	}
+
+	if set.Active == nil {
+		return nil, errors.New("No JWT signing key configured, set JWT_PRIVATE_KEY_FILE or API_SECRET")
+	}
+	return set, nil
+}
+
+//Function to read RSA or Ed25519 key from PEM file
+func readKeyFile(file string) (*SigningKey, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	key, err := ParseKeyPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+	return key, nil
+}
+
+//Function to parse RSA or Ed25519 key, private or public, from PEM data
+func ParseKeyPEM(data []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("Key must be PEM encoded")
+	}
+
+	var private, public interface{}
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		private = key
+	} else if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		private = key
+	} else if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		public = key
+	} else if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		public = key
+	} else {
+		return nil, errors.New("Key format is not supported")
+	}
+
+	key := &SigningKey{Private: private}
+	switch k := private.(type) {
+	case *rsa.PrivateKey:
+		public = &k.PublicKey
+	case ed25519.PrivateKey:
+		public = k.Public()
+	}
+
+	switch public.(type) {
+	case *rsa.PublicKey:
+		key.Method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		key.Method = SigningMethodEd25519
+	default:
+		return nil, errors.New("Only RSA and Ed25519 keys are supported")
+	}
+	key.Public = public
+
+	jwk := key.JWK()
+	key.ID = jwk.Kid
+	return key, nil
+}
+
+//Function to convert public part of key into JWK, kid is the RFC 7638 thumbprint
+func (k *SigningKey) JWK() JWK {
+	var jwk JWK
+	var thumbprint []byte
+	switch public := k.Public.(type) {
+	case *rsa.PublicKey:
+		jwk = JWK{
+			Kty: "RSA",
+			N:   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+		}
+		thumbprint, _ = json.Marshal(struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N})
+	case ed25519.PublicKey:
+		jwk = JWK{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(public),
+		}
+		thumbprint, _ = json.Marshal(struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Crv, jwk.Kty, jwk.X})
+	default:
+		return jwk
+	}
+	sum := sha256.Sum256(thumbprint)
+	jwk.Kid = base64.RawURLEncoding.EncodeToString(sum[:])
+	jwk.Use = "sig"
+	jwk.Alg = k.Method.Alg()
+	return jwk
+}
+
+//Function to list public keys that other services can use to verify tokens
+func PublicJWKS() (JWKS, error) {
+	set, err := keys()
+	if err != nil {
+		return JWKS{}, err
+	}
+	jwks := JWKS{Keys: []JWK{}}
+	for _, key := range set.Keys {
+		if key.ID == "" {
+			continue //shared secret is never published
+		}
+		jwks.Keys = append(jwks.Keys, key.JWK())
+	}
+	sort.Slice(jwks.Keys, func(i, j int) bool { return jwks.Keys[i].Kid < jwks.Keys[j].Kid })
+	return jwks, nil
+}
+
+//Function to find key used to verify token
+func (s *KeySet) verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := s.Keys[kid]
+	if !ok {
+		return nil, errors.New("Unknown signing key")
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, errors.New("Unexpected signing method")
+	}
+	return key.Public, nil
+}
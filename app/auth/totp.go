@@ -0,0 +1,155 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+const (
+	totpPeriod = 30 //seconds of one time step
+	totpDigits = 6
+	totpSkew   = 1 //steps before and after current one still accepted, for clock drift
+
+	RecoveryCodeCount = 10 //recovery codes given when two-factor is enabled
+)
+
+//Secrets and recovery codes are written in unpadded base32
+var base32NoPad = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+//Function to generate random TOTP secret, encoded in base32 like authenticator apps expect
+func GenerateTOTPSecret() (string, error) {
+	bytes := make([]byte, 20)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return base32NoPad.EncodeToString(bytes), nil
+}
+
+//Function to get issuer shown by authenticator apps
+func totpIssuer() string {
+	if name := os.Getenv("TOTP_ISSUER"); name != "" {
+		return name
+	}
+	return issuer()
+}
+
+//Function to build otpauth URI of secret, the URI is what QR code given to authenticator app contains
+func TOTPURI(secret string, account string) string {
+	label := url.PathEscape(totpIssuer()) + ":" + url.PathEscape(account)
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", totpIssuer())
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(totpDigits))
+	query.Set("period", fmt.Sprint(totpPeriod))
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+//Function to get time step of time, RFC 6238
+func TOTPStep(at time.Time) int64 {
+	return at.Unix() / totpPeriod
+}
+
+//Function to compute code of secret at time step, RFC 4226 HOTP with the step as counter
+func totpCode(key []byte, step int64) string {
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000)
+}
+
+//Function to check code against secret around time at, returns time step the code belongs to
+func ValidateTOTP(secret string, code string, at time.Time) (int64, bool) {
+	key, err := base32NoPad.DecodeString(strings.ToUpper(secret))
+	if err != nil || len(code) != totpDigits {
+		return 0, false
+	}
+	current := TOTPStep(at)
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if subtle.ConstantTimeCompare([]byte(totpCode(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+//Function to generate recovery codes of user, returns the codes shown once to user and their hashes to store
+func GenerateRecoveryCodes(userID string, count int) (codes []string, hashed []string, err error) {
+	for i := 0; i < count; i++ {
+		bytes := make([]byte, 10) //80 bits
+		if _, err = rand.Read(bytes); err != nil {
+			return nil, nil, err
+		}
+		code := strings.ToLower(base32NoPad.EncodeToString(bytes)) //16 characters
+		code = code[:4] + "-" + code[4:8] + "-" + code[8:12] + "-" + code[12:]
+		codes = append(codes, code)
+		hashed = append(hashed, HashRecoveryCode(userID, code))
+	}
+	return
+}
+
+//Function to normalize recovery code, case and separators typed by user don't matter
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+//Function to hash recovery code of user, id of user salts the hash so equal codes of two users differ
+func HashRecoveryCode(userID string, code string) string {
+	mac := hmac.New(sha256.New, []byte(userID))
+	mac.Write([]byte(normalizeRecoveryCode(code)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+//Function to hash recovery code the way codes given before they were salted were hashed
+func HashLegacyRecoveryCode(code string) string {
+	return HashToken(normalizeRecoveryCode(code))
+}
+
+//Function to get audience of login challenge, different from access token so one can't be used as the other
+func challengeAudience() string {
+	return audience() + ":two-factor"
+}
+
+//Function to generate token proving that password of user was accepted, exchanged with two-factor code for access token
+func GenerateChallengeToken(userID string) (string, error) {
+	now := time.Now()
+	return sign(&jwt.StandardClaims{
+		Subject:   userID,
+		Issuer:    issuer(),
+		Audience:  challengeAudience(),
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(ChallengeTTL).Unix(),
+	})
+}
+
+//Function to parse login challenge token, returns id of user
+func ParseChallengeToken(signedToken string) (string, error) {
+	claims := &jwt.StandardClaims{}
+	err := parseAudience(signedToken, claims, challengeAudience())
+	if err != nil {
+		return "", err
+	}
+	if claims.Subject == "" {
+		return "", errors.New("Token has no subject")
+	}
+	return claims.Subject, nil
+}
@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+//Secret of RFC 6238 test vectors, ASCII "12345678901234567890" in base32
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+//SHA-1 vectors of RFC 6238 appendix B, codes are the last 6 of the 8 digits listed there
+var rfcVectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},
+	{1111111109, "081804"},
+	{1111111111, "050471"},
+	{1234567890, "005924"},
+	{2000000000, "279037"},
+	{20000000000, "353130"},
+}
+
+func TestValidateTOTPAcceptsRFCVectors(t *testing.T) {
+	for _, vector := range rfcVectors {
+		at := time.Unix(vector.unix, 0)
+		step, ok := ValidateTOTP(rfcSecret, vector.code, at)
+		if !ok {
+			t.Fatalf("Code %s at %d is refused", vector.code, vector.unix)
+		}
+		if step != TOTPStep(at) {
+			t.Fatalf("Code %s at %d belongs to step %d, want %d", vector.code, vector.unix, step, TOTPStep(at))
+		}
+	}
+}
+
+func TestValidateTOTPAllowsOneStepOfDrift(t *testing.T) {
+	for _, vector := range rfcVectors {
+		at := time.Unix(vector.unix, 0)
+		if _, ok := ValidateTOTP(rfcSecret, vector.code, at.Add(totpPeriod*time.Second)); !ok {
+			t.Fatalf("Code %s at %d is refused one step later", vector.code, vector.unix)
+		}
+		if _, ok := ValidateTOTP(rfcSecret, vector.code, at.Add(2*totpPeriod*time.Second)); ok {
+			t.Fatalf("Code %s at %d is accepted two steps later", vector.code, vector.unix)
+		}
+	}
+}
+
+func TestValidateTOTPRefusesWrongCode(t *testing.T) {
+	at := time.Unix(59, 0)
+	for _, code := range []string{"287083", "28708", "2870821", "", "abcdef"} {
+		if _, ok := ValidateTOTP(rfcSecret, code, at); ok {
+			t.Fatalf("Code %q is accepted", code)
+		}
+	}
+	if _, ok := ValidateTOTP("not base32!", "287082", at); ok {
+		t.Fatalf("Code of invalid secret is accepted")
+	}
+}
+
+func TestRecoveryCodeHashIsSaltedByUser(t *testing.T) {
+	codes, hashed, err := GenerateRecoveryCodes("user-1", RecoveryCodeCount)
+	if err != nil {
+		t.Fatalf("Generating recovery codes error: %v", err)
+	}
+	if len(codes) != RecoveryCodeCount || len(hashed) != RecoveryCodeCount {
+		t.Fatalf("Generated %d codes and %d hashes, want %d", len(codes), len(hashed), RecoveryCodeCount)
+	}
+	for i, code := range codes {
+		if len(normalizeRecoveryCode(code)) != 16 {
+			t.Fatalf("Code %s doesn't carry 80 bits", code)
+		}
+		typed := strings.ToUpper(strings.ReplaceAll(code, "-", " "))
+		if HashRecoveryCode("user-1", typed) != hashed[i] {
+			t.Fatalf("Code %s typed as %s has another hash", code, typed)
+		}
+		if HashRecoveryCode("user-2", code) == hashed[i] {
+			t.Fatalf("Code %s has the same hash for another user", code)
+		}
+	}
+}
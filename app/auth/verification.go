@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+//Claims of email verification link, link only verifies the email it was sent to
+type VerifyClaims struct {
+	Email string `json:"email"`
+	jwt.StandardClaims
+}
+
+//Function to get audience of verification token, different from access token so one can't be used as the other
+func verifyAudience() string {
+	return audience() + ":email-verification"
+}
+
+//Function to generate signed token of email verification link
+func GenerateVerifyToken(userID string, email string) (string, error) {
+	now := time.Now()
+	return sign(&VerifyClaims{
+		Email: email,
+		StandardClaims: jwt.StandardClaims{
+			Subject:   userID,
+			Issuer:    issuer(),
+			Audience:  verifyAudience(),
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(VerifyTokenTTL).Unix(),
+		},
+	})
+}
+
+//Function to parse token of email verification link
+func ParseVerifyToken(signedToken string) (*VerifyClaims, error) {
+	claims := &VerifyClaims{}
+	err := parseAudience(signedToken, claims, verifyAudience())
+	if err != nil {
+		return nil, err
+	}
+	if claims.Subject == "" || claims.Email == "" {
+		return nil, errors.New("Couldn't parse claims token")
+	}
+	return claims, nil
+}
+
+//Claims of email change link, link only changes the email user still has to the one it was sent to
+type EmailChangeClaims struct {
+	Email         string `json:"email"`
+	PreviousEmail string `json:"previous_email"`
+	jwt.StandardClaims
+}
+
+//Function to get audience of email change token
+func emailChangeAudience() string {
+	return audience() + ":email-change"
+}
+
+//Function to generate signed token of email change link, it is valid as long as verification link
+func GenerateEmailChangeToken(userID string, previousEmail string, email string) (string, error) {
+	now := time.Now()
+	return sign(&EmailChangeClaims{
+		Email:         email,
+		PreviousEmail: previousEmail,
+		StandardClaims: jwt.StandardClaims{
+			Subject:   userID,
+			Issuer:    issuer(),
+			Audience:  emailChangeAudience(),
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(VerifyTokenTTL).Unix(),
+		},
+	})
+}
+
+//Function to parse token of email change link
+func ParseEmailChangeToken(signedToken string) (*EmailChangeClaims, error) {
+	claims := &EmailChangeClaims{}
+	err := parseAudience(signedToken, claims, emailChangeAudience())
+	if err != nil {
+		return nil, err
+	}
+	if claims.Subject == "" || claims.Email == "" || claims.PreviousEmail == "" {
+		return nil, errors.New("Couldn't parse claims token")
+	}
+	return claims, nil
+}
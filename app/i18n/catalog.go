@@ -0,0 +1,263 @@
+package i18n
+
+//Messages of every language by key, {name} is replaced by param of the same name
+var catalog = map[string]map[string]string{
+	LangEN: {
+		//Field labels, {field} of validation messages
+		"field.id":               "User ID",
+		"field.username":         "Username",
+		"field.email":            "Email",
+		"field.password":         "Password",
+		"field.role":             "Role",
+		"field.refresh_token":    "Refresh token",
+		"field.title":            "Title",
+		"field.caption":          "Caption",
+		"field.photo_url":        "Photo URL",
+		"field.photo":            "Photo",
+		"field.token":            "Token",
+		"field.code":             "Code",
+		"field.challenge_token":  "Challenge token",
+		"field.current_password": "Current password",
+		"field.new_password":     "New password",
+
+		//Request and validation
+		"request.unreadable":  "Request body can't be read",
+		"request.invalid":     "Request body must be valid JSON",
+		"request.too_large":   "Request body is too large",
+		"request.malformed":   "Request body must be a valid multipart form",
+		"validation.required": "{field} is required",
+		"validation.invalid":  "{field} is invalid",
+		"validation.min":      "{field} must be at least {param} characters",
+		"validation.max":      "{field} must be at most {param} characters",
+		"validation.scheme":   "{field} must be a {param} URL",
+		"validation.type":     "{field} must be {param}",
+		"validation.datetime": "{field} must be a date (YYYY-MM-DD) or RFC3339 time",
+		"pagination.limit":    "Limit must be between 1 and {max}",
+		"pagination.sort":     "Sort field {sort} is not allowed",
+		"pagination.cursor":   "Cursor is invalid",
+		"photo.variant":       "Size must be one of 64, 128 or 512 and format one of jpeg or webp",
+		"photo.too_large":     "Photo must not be larger than {max} bytes",
+		"photo.empty":         "Photo is empty",
+		"photo.type":          "Photo type {type} is not allowed",
+		"photo.undecodable":   "Photo can't be decoded or its dimension is too large",
+
+		//Data
+		"data.retrieved":  "Data retrieved successfully",
+		"data.not_found":  "Data not found",
+		"data.exists":     "{field} already exist",
+		"data.related":    "Related data doesn't exist",
+		"user.not_found":  "User with id {id} not found",
+		"photo.not_found": "Photo with id {id} not found",
+		"error.internal":  "Internal server error",
+
+		//Authentication and authorization
+		"auth.email_not_found":    "User with email {email} not found",
+		"auth.wrong_password":     "Password is incorrect",
+		"auth.suspended":          "User has been suspended",
+		"auth.locked":             "Too many failed login attempts, try again in {seconds} seconds",
+		"auth.token_missing":      "Token not found",
+		"auth.token_invalid":      "Token is invalid",
+		"auth.token_expired":      "Token has expired",
+		"auth.token_revoked":      "Token has been revoked",
+		"auth.user_not_found":     "User not found",
+		"auth.no_access":          "You don't have access to this resource",
+		"auth.refresh_invalid":    "Refresh token is invalid",
+		"auth.refresh_revoked":    "Refresh token has been revoked",
+		"auth.refresh_expired":    "Refresh token has expired",
+		"user.update_forbidden":   "You can't update another user",
+		"user.delete_forbidden":   "You can't delete another user",
+		"photo.change_forbidden":  "You can't change photo of another user",
+		"photo.delete_forbidden":  "You can't delete photo of another user",
+		"photo.profile_forbidden": "You can't change profile photo of another user",
+		"password.reset_invalid":  "Reset link is invalid or has expired",
+		"password.reset_limited":  "Too many reset requests, try again in {seconds} seconds",
+		"email.unverified":        "Please verify your email first",
+		"email.verify_invalid":    "Verification link is invalid",
+		"email.verify_expired":    "Verification link has expired, please ask for a new one",
+		"email.already_verified":  "Email has been verified already",
+		"email.resend_limited":    "Verification link has just been sent, try again in {seconds} seconds",
+		"email.unchanged":         "New email is the same as your current email",
+
+		//Success
+		"user.logged_in":          "Login successfully",
+		"user.logged_out":         "Logout successfully",
+		"user.registered":         "User registered succesfully",
+		"user.updated":            "User updated succesfully",
+		"user.deleted":            "User deleted succesfully",
+		"user.role_updated":       "Role updated successfully",
+		"user.suspended":          "User suspended successfully",
+		"user.unsuspended":        "User unsuspended successfully",
+		"user.unlocked":           "User unlocked successfully",
+		"token.refreshed":         "Token refreshed successfully",
+		"photo.uploaded":          "Photo uploaded successfully",
+		"photo.updated":           "Photo updated successfully",
+		"photo.deleted":           "Photo deleted successfully",
+		"photo.profile_changed":   "Profile photo changed successfully",
+		"password.reset_sent":     "If the email is registered, a reset link has been sent to it",
+		"password.reset_done":     "Password has been reset, please login again",
+		"email.verified":          "Email verified successfully",
+		"email.verification_sent": "Verification link has been sent to your email",
+		"password.changed":        "Password changed successfully, other sessions have been logged out",
+		"email.change_sent":       "Confirmation link has been sent to your new email, the email changes once it is opened",
+		"email.changed":           "Email changed successfully",
+
+		//Two-factor authentication
+		"auth.challenge_invalid":   "Login session is invalid, please login again",
+		"auth.challenge_expired":   "Login session has expired, please login again",
+		"totp.code_invalid":        "Code is invalid or has been used",
+		"totp.already_enabled":     "Two-factor authentication is enabled already",
+		"totp.not_enabled":         "Two-factor authentication is not enabled",
+		"totp.setup_required":      "Set up two-factor authentication first",
+		"auth.two_factor_required": "Enter the code from your authenticator app",
+		"totp.setup_started":       "Scan the QR code with your authenticator app, then confirm with a code",
+		"totp.enabled":             "Two-factor authentication enabled, keep the recovery codes in a safe place",
+		"totp.disabled":            "Two-factor authentication disabled",
+
+		//Password policy
+		"password.lower":    "{field} must contain a lowercase letter",
+		"password.upper":    "{field} must contain an uppercase letter",
+		"password.digit":    "{field} must contain a digit",
+		"password.symbol":   "{field} must contain a symbol",
+		"password.username": "{field} must not contain your username",
+		"password.email":    "{field} must not contain your email",
+		"password.breached": "{field} has appeared in a data breach, please choose another one",
+		"password.reused":   "{field} must not be one of your last {count} passwords",
+
+		//Mail
+		"mail.verify_subject":       "Verify your email",
+		"mail.verify_body":          "Hi {username},\n\nPlease confirm that this is your email by opening the link below:\n\n{link}\n\nThe link expires in {hours} hours.\n",
+		"mail.reset_subject":        "Reset your password",
+		"mail.reset_body":           "Hi {username},\n\nWe received a request to reset your password. Open the link below to choose a new password:\n\n{link}\n\nThe link expires in {minutes} minutes and can be used once. If you didn't ask for it, you can ignore this mail.\n",
+		"mail.email_change_subject": "Confirm your new email",
+		"mail.email_change_body":    "Hi {username},\n\nPlease confirm that you want to use {email} for your account by opening the link below:\n\n{link}\n\nThe link expires in {hours} hours. Your email doesn't change until then.\n",
+	},
+	LangID: {
+		//Field labels, {field} of validation messages
+		"field.id":               "ID pengguna",
+		"field.username":         "Nama pengguna",
+		"field.email":            "Email",
+		"field.password":         "Kata sandi",
+		"field.role":             "Peran",
+		"field.refresh_token":    "Refresh token",
+		"field.title":            "Judul",
+		"field.caption":          "Keterangan",
+		"field.photo_url":        "URL foto",
+		"field.photo":            "Foto",
+		"field.token":            "Token",
+		"field.code":             "Kode",
+		"field.challenge_token":  "Token tantangan",
+		"field.current_password": "Kata sandi saat ini",
+		"field.new_password":     "Kata sandi baru",
+
+		//Request and validation
+		"request.unreadable":  "Isi permintaan tidak dapat dibaca",
+		"request.invalid":     "Isi permintaan harus berupa JSON yang valid",
+		"request.too_large":   "Isi permintaan terlalu besar",
+		"request.malformed":   "Isi permintaan harus berupa form multipart yang valid",
+		"validation.required": "{field} wajib diisi",
+		"validation.invalid":  "{field} tidak valid",
+		"validation.min":      "{field} minimal {param} karakter",
+		"validation.max":      "{field} maksimal {param} karakter",
+		"validation.scheme":   "{field} harus berupa URL {param}",
+		"validation.type":     "{field} harus bertipe {param}",
+		"validation.datetime": "{field} harus berupa tanggal (YYYY-MM-DD) atau waktu RFC3339",
+		"pagination.limit":    "Limit harus antara 1 dan {max}",
+		"pagination.sort":     "Pengurutan berdasarkan {sort} tidak diizinkan",
+		"pagination.cursor":   "Cursor tidak valid",
+		"photo.variant":       "Ukuran harus 64, 128 atau 512 dan format harus jpeg atau webp",
+		"photo.too_large":     "Ukuran foto tidak boleh lebih dari {max} byte",
+		"photo.empty":         "Foto kosong",
+		"photo.type":          "Tipe foto {type} tidak diizinkan",
+		"photo.undecodable":   "Foto tidak dapat dibaca atau dimensinya terlalu besar",
+
+		//Data
+		"data.retrieved":  "Data berhasil diambil",
+		"data.not_found":  "Data tidak ditemukan",
+		"data.exists":     "{field} sudah terdaftar",
+		"data.related":    "Data terkait tidak ditemukan",
+		"user.not_found":  "Pengguna dengan id {id} tidak ditemukan",
+		"photo.not_found": "Foto dengan id {id} tidak ditemukan",
+		"error.internal":  "Terjadi kesalahan pada server",
+
+		//Authentication and authorization
+		"auth.email_not_found":    "Pengguna dengan email {email} tidak ditemukan",
+		"auth.wrong_password":     "Kata sandi salah",
+		"auth.suspended":          "Pengguna telah ditangguhkan",
+		"auth.locked":             "Terlalu banyak percobaan masuk yang gagal, coba lagi dalam {seconds} detik",
+		"auth.token_missing":      "Token tidak ditemukan",
+		"auth.token_invalid":      "Token tidak valid",
+		"auth.token_expired":      "Token telah kedaluwarsa",
+		"auth.token_revoked":      "Token telah dicabut",
+		"auth.user_not_found":     "Pengguna tidak ditemukan",
+		"auth.no_access":          "Anda tidak memiliki akses ke sumber ini",
+		"auth.refresh_invalid":    "Refresh token tidak valid",
+		"auth.refresh_revoked":    "Refresh token telah dicabut",
+		"auth.refresh_expired":    "Refresh token telah kedaluwarsa",
+		"user.update_forbidden":   "Anda tidak dapat mengubah pengguna lain",
+		"user.delete_forbidden":   "Anda tidak dapat menghapus pengguna lain",
+		"photo.change_forbidden":  "Anda tidak dapat mengubah foto pengguna lain",
+		"photo.delete_forbidden":  "Anda tidak dapat menghapus foto pengguna lain",
+		"photo.profile_forbidden": "Anda tidak dapat mengubah foto profil pengguna lain",
+		"password.reset_invalid":  "Tautan reset tidak valid atau telah kedaluwarsa",
+		"password.reset_limited":  "Terlalu banyak permintaan reset, coba lagi dalam {seconds} detik",
+		"email.unverified":        "Silakan verifikasi email Anda terlebih dahulu",
+		"email.verify_invalid":    "Tautan verifikasi tidak valid",
+		"email.verify_expired":    "Tautan verifikasi telah kedaluwarsa, silakan minta tautan baru",
+		"email.already_verified":  "Email sudah diverifikasi",
+		"email.resend_limited":    "Tautan verifikasi baru saja dikirim, coba lagi dalam {seconds} detik",
+		"email.unchanged":         "Email baru sama dengan email Anda saat ini",
+
+		//Success
+		"user.logged_in":          "Berhasil masuk",
+		"user.logged_out":         "Berhasil keluar",
+		"user.registered":         "Pengguna berhasil didaftarkan",
+		"user.updated":            "Pengguna berhasil diperbarui",
+		"user.deleted":            "Pengguna berhasil dihapus",
+		"user.role_updated":       "Peran berhasil diperbarui",
+		"user.suspended":          "Pengguna berhasil ditangguhkan",
+		"user.unsuspended":        "Penangguhan pengguna berhasil dibatalkan",
+		"user.unlocked":           "Penguncian pengguna berhasil dibuka",
+		"token.refreshed":         "Token berhasil diperbarui",
+		"photo.uploaded":          "Foto berhasil diunggah",
+		"photo.updated":           "Foto berhasil diperbarui",
+		"photo.deleted":           "Foto berhasil dihapus",
+		"photo.profile_changed":   "Foto profil berhasil diubah",
+		"password.reset_sent":     "Jika email terdaftar, tautan reset telah dikirim ke email tersebut",
+		"password.reset_done":     "Kata sandi berhasil direset, silakan masuk kembali",
+		"email.verified":          "Email berhasil diverifikasi",
+		"email.verification_sent": "Tautan verifikasi telah dikirim ke email Anda",
+		"password.changed":        "Kata sandi berhasil diubah, sesi lain telah dikeluarkan",
+		"email.change_sent":       "Tautan konfirmasi telah dikirim ke email baru Anda, email berubah setelah tautan dibuka",
+		"email.changed":           "Email berhasil diubah",
+
+		//Two-factor authentication
+		"auth.challenge_invalid":   "Sesi masuk tidak valid, silakan masuk kembali",
+		"auth.challenge_expired":   "Sesi masuk telah kedaluwarsa, silakan masuk kembali",
+		"totp.code_invalid":        "Kode tidak valid atau sudah digunakan",
+		"totp.already_enabled":     "Autentikasi dua faktor sudah aktif",
+		"totp.not_enabled":         "Autentikasi dua faktor belum aktif",
+		"totp.setup_required":      "Siapkan autentikasi dua faktor terlebih dahulu",
+		"auth.two_factor_required": "Masukkan kode dari aplikasi autentikator Anda",
+		"totp.setup_started":       "Pindai kode QR dengan aplikasi autentikator Anda, lalu konfirmasi dengan kode",
+		"totp.enabled":             "Autentikasi dua faktor diaktifkan, simpan kode pemulihan di tempat yang aman",
+		"totp.disabled":            "Autentikasi dua faktor dinonaktifkan",
+
+		//Password policy
+		"password.lower":    "{field} harus mengandung huruf kecil",
+		"password.upper":    "{field} harus mengandung huruf besar",
+		"password.digit":    "{field} harus mengandung angka",
+		"password.symbol":   "{field} harus mengandung simbol",
+		"password.username": "{field} tidak boleh mengandung nama pengguna Anda",
+		"password.email":    "{field} tidak boleh mengandung email Anda",
+		"password.breached": "{field} pernah bocor dalam pelanggaran data, silakan pilih yang lain",
+		"password.reused":   "{field} tidak boleh sama dengan {count} kata sandi terakhir Anda",
+
+		//Mail
+		"mail.verify_subject":       "Verifikasi email Anda",
+		"mail.verify_body":          "Halo {username},\n\nSilakan konfirmasi bahwa ini adalah email Anda dengan membuka tautan berikut:\n\n{link}\n\nTautan berlaku selama {hours} jam.\n",
+		"mail.reset_subject":        "Reset kata sandi Anda",
+		"mail.reset_body":           "Halo {username},\n\nKami menerima permintaan untuk mereset kata sandi Anda. Buka tautan berikut untuk membuat kata sandi baru:\n\n{link}\n\nTautan berlaku selama {minutes} menit dan hanya dapat digunakan sekali. Jika Anda tidak memintanya, abaikan email ini.\n",
+		"mail.email_change_subject": "Konfirmasi email baru Anda",
+		"mail.email_change_body":    "Halo {username},\n\nSilakan konfirmasi bahwa Anda ingin menggunakan {email} untuk akun Anda dengan membuka tautan di bawah ini:\n\n{link}\n\nTautan berlaku selama {hours} jam. Email Anda tidak berubah sebelum tautan dibuka.\n",
+	},
+}
@@ -0,0 +1,66 @@
+package i18n
+
+import (
+	"os"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+const (
+	LangEN = "en"
+	LangID = "id"
+)
+
+//Values put into {name} placeholders of a message
+type Params map[string]string
+
+//Languages in the order they are matched, first one is used when nothing matches
+var supported = []language.Tag{language.English, language.Indonesian}
+
+var matcher = language.NewMatcher(supported)
+
+//Function to get language used when request doesn't ask for a supported one, set by DEFAULT_LANGUAGE
+func Fallback() string {
+	if lang := strings.ToLower(os.Getenv("DEFAULT_LANGUAGE")); catalog[lang] != nil {
+		return lang
+	}
+	return LangEN
+}
+
+//Function to choose supported language from Accept-Language header
+func Negotiate(header string) string {
+	tags, _, err := language.ParseAcceptLanguage(header)
+	if err != nil || len(tags) == 0 {
+		return Fallback()
+	}
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return Fallback()
+	}
+	base, _ := supported[index].Base()
+	return base.String()
+}
+
+//Function to translate message key, key is returned as is when no language has it
+func T(lang string, key string, params Params) string {
+	message, ok := catalog[lang][key]
+	if !ok {
+		message, ok = catalog[Fallback()][key]
+	}
+	if !ok {
+		message = key
+	}
+	for name, value := range params {
+		message = strings.ReplaceAll(message, "{"+name+"}", value)
+	}
+	return message
+}
+
+//Function to get label of request field, field name is used when catalog has no label
+func Label(lang string, field string) string {
+	if _, ok := catalog[LangEN]["field."+field]; !ok {
+		return field
+	}
+	return T(lang, "field."+field, nil)
+}
@@ -0,0 +1,37 @@
+package rbac
+
+const (
+	RoleUser  = "user"  //default role for registered user
+	RoleAdmin = "admin" //role for support staff
+)
+
+const (
+	ListUsers    = "users:list"    //list every user
+	SuspendUsers = "users:suspend" //suspend and unsuspend user
+	UnlockUsers  = "users:unlock"  //unlock login locked after failed attempts
+	DeleteUsers  = "users:delete"  //delete any user
+	UpdateRoles  = "users:role"    //change role of any user
+	DeletePhotos = "photos:delete" //remove any photo
+)
+
+//Permissions granted to each role
+var permissions = map[string][]string{
+	RoleAdmin: {ListUsers, SuspendUsers, UnlockUsers, DeleteUsers, UpdateRoles, DeletePhotos},
+	RoleUser:  {},
+}
+
+//Function to check if role exist
+func IsRole(role string) bool {
+	_, ok := permissions[role]
+	return ok
+}
+
+//Function to check if role is granted a permission
+func Can(role string, permission string) bool {
+	for _, p := range permissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package response
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"task-vix-btpns/app/apperror"
+	"task-vix-btpns/app/i18n"
+)
+
+const (
+	StatusSuccess = "Success"
+	StatusError   = "Error"
+)
+
+//Body of every JSON response
+type Envelope struct {
+	Status  string                `json:"status"`
+	Code    apperror.Code         `json:"code,omitempty"`
+	Message string                `json:"message"`
+	Errors  []apperror.FieldError `json:"errors,omitempty"` //invalid request fields
+	Data    interface{}           `json:"data"`
+	Meta    interface{}           `json:"meta,omitempty"`
+}
+
+//Function to choose language of response from Accept-Language header
+func Language(c *gin.Context) string {
+	lang := i18n.Negotiate(c.GetHeader("Accept-Language"))
+	c.Header("Content-Language", lang)
+	c.Header("Vary", "Accept-Language")
+	return lang
+}
+
+//Function to write successful response, message is a catalog key
+func Success(c *gin.Context, status int, message string, data interface{}) {
+	lang := Language(c)
+	c.JSON(status, Envelope{Status: StatusSuccess, Message: i18n.T(lang, message, nil), Data: data})
+}
+
+//Function to write successful response of a list page
+func Page(c *gin.Context, status int, message string, data interface{}, meta interface{}) {
+	lang := Language(c)
+	c.JSON(status, Envelope{Status: StatusSuccess, Message: i18n.T(lang, message, nil), Data: data, Meta: meta})
+}
+
+//Function to write error response, status comes from error code
+func Error(c *gin.Context, err *apperror.Error) {
+	if err.RetryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(err.RetryAfter.Seconds()))))
+	}
+	message, fields := err.Localize(Language(c))
+	c.JSON(err.Status(), Envelope{Status: StatusError, Code: err.Code, Message: message, Errors: fields})
+}
@@ -8,6 +8,13 @@ type Photo struct {
 	PhotoUrl string `json:"photo_url"`
 }
 
+type PhotoRequest struct {
+	Title     string `json:"title"`
+	Caption   string `json:"caption"`
+	PhotoUrl  string `json:"photo_url"`
+	IsProfile bool   `json:"is_profile"`
+}
+
 type Owner struct {
 	ID       string `json:"id"`
 	Username string `json:"username"`
@@ -15,28 +22,129 @@ type Owner struct {
 }
 
 type UserData struct {
-	ID       string `json:"id"`
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	Photos   Photo  `json:"photos"`
-	Token    string `json:"token"`
+	ID           string `json:"id"`
+	Username     string `json:"username"`
+	Email        string `json:"email"`
+	Photos       Photo  `json:"photos"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
 }
 
-type UserLogin struct {
-	ID       string `json:"id"`
-	Username string `json:"users.username"`
-	Email    string `json:"users.email"`
-	Token    string `json:"users.token"`
-	Password string `json:"users.password"`
-	Title    string `json:"photos.title"`
-	Caption  string `json:"photos.caption"`
-	PhotoUrl string `json:"photos.photo_url"`
+type UserRegister struct {
+	ID               string    `json:"id"`
+	Username         string    `json:"username"`
+	Email            string    `json:"email"`
+	EmailVerified    bool      `json:"email_verified"`
+	TwoFactorEnabled bool      `json:"two_factor_enabled"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
 }
 
-type UserRegister struct {
+//Profile of user shown to other users
+type UserPublic struct {
 	ID        string    `json:"id"`
 	Username  string    `json:"username"`
-	Email     string    `json:"email"`
 	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-}
\ No newline at end of file
+}
+
+type UserAdmin struct {
+	ID              string     `json:"id"`
+	Username        string     `json:"username"`
+	Email           string     `json:"email"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	Role            string     `json:"role"`
+	SuspendedAt     *time.Time `json:"suspended_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+type RegisterRequest struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type UpdateUserRequest struct {
+	Username string `json:"username"` //email and password have their own endpoints
+}
+
+type RoleRequest struct {
+	Role string `json:"role"`
+}
+
+type TokenPair struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" forgot:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" reset:"required"`
+	Password string `json:"password" reset:"required"`
+}
+
+type ProfileRequest struct {
+	Username string `json:"username" profile:"required,max=255"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" password:"required"`
+	NewPassword     string `json:"new_password" password:"required"`
+}
+
+type ChangeEmailRequest struct {
+	Email    string `json:"email" email:"required,email,max=255"`
+	Password string `json:"password" email:"required"` //current password
+}
+
+type LoginChallenge struct {
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	ChallengeToken    string `json:"challenge_token"`
+	ExpiresIn         int    `json:"expires_in"` //seconds
+}
+
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" challenge:"required"`
+	Code           string `json:"code" challenge:"required"` //TOTP or recovery code
+}
+
+type TwoFactorSetup struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauth_uri"` //content of QR code scanned by authenticator app
+}
+
+type TwoFactorSetupRequest struct {
+	CurrentPassword string `json:"current_password" setup:"required"`
+}
+
+type TwoFactorEnableRequest struct {
+	CurrentPassword string `json:"current_password" enable:"required"`
+	Code            string `json:"code" enable:"required"`
+}
+
+type TwoFactorDisableRequest struct {
+	Password string `json:"password" disable:"required"`
+	Code     string `json:"code" disable:"required"` //TOTP or recovery code
+}
+
+type RecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type PageMeta struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor"`
+	Total      *int   `json:"total,omitempty"`
+}
@@ -0,0 +1,254 @@
+package controllers
+
+import (
+	"errors"
+	"html"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"task-vix-btpns/app"
+	"task-vix-btpns/app/apperror"
+	"task-vix-btpns/app/auth"
+	"task-vix-btpns/app/i18n"
+	"task-vix-btpns/app/response"
+	"task-vix-btpns/helpers/validation"
+	"task-vix-btpns/lockout"
+	"task-vix-btpns/mailer"
+	"task-vix-btpns/middlewares"
+	"task-vix-btpns/models"
+	"task-vix-btpns/repository"
+)
+
+//Handlers of endpoints where user who has login manages own account
+type AccountController struct {
+	users  repository.UserRepository
+	tokens repository.TokenRepository
+	mail   mailer.Mailer
+	guard  *lockout.Guard
+}
+
+//Function to create account handlers
+func NewAccountController(repos repository.Repositories, mail mailer.Mailer, guard *lockout.Guard) *AccountController {
+	return &AccountController{users: repos.Users, tokens: repos.Tokens, mail: mail, guard: guard}
+}
+
+//Function to update profile of user who has login, email and password have their own endpoints
+func (ctl *AccountController) UpdateProfile(c *gin.Context) {
+	//Convert json body to object
+	input := app.ProfileRequest{}
+	err := bindJSON(c, &input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	input.Username = html.EscapeString(strings.TrimSpace(input.Username)) //Escape string
+	err = validation.Struct("profile", &input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	//Get user data from AuthMiddleware
+	user_has_login := middlewares.CurrentUser(c)
+
+	//Update profile
+	err = ctl.users.Update(&models.User{ID: user_has_login.ID, Username: input.Username})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	user, err := ctl.users.FindByID(user_has_login.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	//Response success
+	response.Success(c, http.StatusOK, "user.updated", toUserRegister(user))
+}
+
+//Function to change password of user who has login, other sessions are closed and a new one is returned
+func (ctl *AccountController) ChangePassword(c *gin.Context) {
+	//Convert json body to object
+	input := app.ChangePasswordRequest{}
+	err := bindJSON(c, &input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	err = validation.Struct("password", &input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	//Get user data from AuthMiddleware
+	user_has_login := middlewares.CurrentUser(c)
+
+	//Verify current password
+	err = reauthenticate(c, ctl.guard, user_has_login, "current_password", input.CurrentPassword)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	//Check new password
+	err = checkPasswordPolicy(ctl.users, "new_password", input.NewPassword, user_has_login.Username, user_has_login.Email, user_has_login)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	//Hashing password, access tokens issued before the change are refused
+	changed_at := passwordChangeTime()
+	user := models.User{ID: user_has_login.ID, Password: input.NewPassword, PasswordChangedAt: &changed_at}
+	err = user.HashPassword()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	//Update password
+	err = ctl.users.Update(&user)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	err = recordPasswordChange(ctl.users, user_has_login)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	//Sessions opened with old password are closed, this one continues with new tokens.
+	//Access token of this request may be issued in the same second as the change, so it is revoked by itself.
+	err = ctl.tokens.RevokeUserTokens(user.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	claims := middlewares.CurrentClaims(c)
+	revoked := models.RevokedToken{JTI: claims.Id, ExpiresAt: time.Unix(claims.ExpiresAt, 0)}
+	err = ctl.tokens.RevokeAccessToken(&revoked)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	access_token, err := auth.GenerateJWT(user_has_login.ID, user_has_login.Username, user_has_login.Role)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	_, refresh_token, err := issueRefreshToken(ctl.tokens, user_has_login.ID, "")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	//Response success
+	response.Success(c, http.StatusOK, "password.changed", app.TokenPair{Token: access_token, RefreshToken: refresh_token})
+}
+
+//Function to send confirmation link to new email of user who has login, email changes once the link is opened
+func (ctl *AccountController) ChangeEmail(c *gin.Context) {
+	//Convert json body to object
+	input := app.ChangeEmailRequest{}
+	err := bindJSON(c, &input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	input.Email = html.EscapeString(strings.TrimSpace(input.Email)) //Escape string
+	err = validation.Struct("email", &input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	//Get user data from AuthMiddleware
+	user_has_login := middlewares.CurrentUser(c)
+	if input.Email == user_has_login.Email {
+		c.Error(apperror.Field("email", "unchanged", "email.unchanged"))
+		return
+	}
+
+	//Verify password
+	err = reauthenticate(c, ctl.guard, user_has_login, "password", input.Password)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	//Email of another user can't be taken
+	_, err = ctl.users.FindByEmail(input.Email)
+	if err == nil {
+		c.Error(apperror.Conflict("data.exists", apperror.FieldError{Field: "email", Code: "unique", Message: "data.exists"}))
+		return
+	} else if err != repository.ErrNotFound {
+		c.Error(err)
+		return
+	}
+
+	//Link shares send limit with verification link, so that mails can't be sent to any address repeatedly
+	err = claimVerificationSend(ctl.users, user_has_login)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	//Link carries signed user id with current and new email, nothing is stored
+	token, err := auth.GenerateEmailChangeToken(user_has_login.ID, user_has_login.Email, input.Email)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	lang := response.Language(c)
+	params := i18n.Params{
+		"username": user_has_login.Username,
+		"email":    input.Email,
+		"link":     appURL() + "/users/email/confirm?token=" + url.QueryEscape(token),
+		"hours":    strconv.Itoa(int(auth.VerifyTokenTTL.Hours())),
+	}
+	err = ctl.mail.Send(c.Request.Context(), mailer.Message{
+		To:      input.Email,
+		Subject: i18n.T(lang, "mail.email_change_subject", params),
+		Body:    i18n.T(lang, "mail.email_change_body", params),
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	//Response success
+	response.Success(c, http.StatusOK, "email.change_sent", nil)
+}
+
+//Function to change email with token from confirmation link, new email is verified by the link
+func (ctl *AccountController) ConfirmEmailChange(c *gin.Context) {
+	//Check token of link
+	claims, err := auth.ParseEmailChangeToken(c.Query("token"))
+	if errors.Is(err, auth.ErrTokenExpired) {
+		c.Error(apperror.Field("token", "expired", "email.verify_expired"))
+		return
+	} else if err != nil {
+		c.Error(apperror.Field("token", "invalid", "email.verify_invalid"))
+		return
+	}
+
+	//Link is stale once email of user has changed since it was sent
+	changed, err := ctl.users.ChangeEmail(claims.Subject, claims.PreviousEmail, claims.Email)
+	if err != nil {
+		c.Error(err)
+		return
+	} else if !changed {
+		c.Error(apperror.Field("token", "invalid", "email.verify_invalid"))
+		return
+	}
+
+	//Response success
+	response.Success(c, http.StatusOK, "email.changed", nil)
+}
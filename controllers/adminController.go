@@ -0,0 +1,195 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"task-vix-btpns/app"
+	"task-vix-btpns/app/apperror"
+	"task-vix-btpns/app/rbac"
+	"task-vix-btpns/app/response"
+	"task-vix-btpns/lockout"
+	"task-vix-btpns/models"
+	"task-vix-btpns/repository"
+	"task-vix-btpns/storage"
+)
+
+//Handlers of admin endpoints
+type AdminController struct {
+	users  repository.UserRepository
+	photos repository.PhotoRepository
+	store  storage.Storage
+	guard  *lockout.Guard
+}
+
+//Function to create admin handlers
+func NewAdminController(repos repository.Repositories, store storage.Storage, guard *lockout.Guard) *AdminController {
+	return &AdminController{users: repos.Users, photos: repos.Photos, store: store, guard: guard}
+}
+
+//Function to convert user into admin response data
+func toUserAdmin(user models.User) app.UserAdmin {
+	return app.UserAdmin{
+		ID:              user.ID,
+		Username:        user.Username,
+		Email:           user.Email,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		Role:            user.Role,
+		SuspendedAt:     user.SuspendedAt,
+		CreatedAt:       user.CreatedAt,
+		UpdatedAt:       user.UpdatedAt,
+	}
+}
+
+//Function to list every user
+func (ctl *AdminController) AdminGetUsers(c *gin.Context) {
+	users, err := ctl.users.List()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	data := make([]app.UserAdmin, len(users))
+	for i := range users {
+		data[i] = toUserAdmin(users[i])
+	}
+
+	//Return response
+	response.Success(c, http.StatusOK, "data.retrieved", data)
+}
+
+//Function to suspend or unsuspend user
+func (ctl *AdminController) adminSetSuspended(c *gin.Context, suspended bool) {
+	//Check if user exist
+	user, err := ctl.users.FindByID(c.Param("userId"))
+	if err != nil {
+		c.Error(apperror.NotFound("user.not_found").With("id", c.Param("userId")))
+		return
+	}
+
+	var suspended_at *time.Time
+	message := "user.unsuspended"
+	if suspended {
+		now := time.Now()
+		suspended_at = &now
+		message = "user.suspended"
+	}
+
+	//Update user, suspended user loses every session
+	if err := ctl.users.SetSuspended(user.ID, suspended_at); err != nil {
+		c.Error(err)
+		return
+	}
+	user.SuspendedAt = suspended_at
+
+	//Response success
+	response.Success(c, http.StatusOK, message, toUserAdmin(user))
+}
+
+//Function to suspend user
+func (ctl *AdminController) AdminSuspendUser(c *gin.Context) {
+	ctl.adminSetSuspended(c, true)
+}
+
+//Function to unsuspend user
+func (ctl *AdminController) AdminUnsuspendUser(c *gin.Context) {
+	ctl.adminSetSuspended(c, false)
+}
+
+//Function to unlock login of user locked after failed attempts, locks of IP addresses expire on their own
+func (ctl *AdminController) AdminUnlockUser(c *gin.Context) {
+	//Check if user exist
+	user, err := ctl.users.FindByID(c.Param("userId"))
+	if err != nil {
+		c.Error(apperror.NotFound("user.not_found").With("id", c.Param("userId")))
+		return
+	}
+
+	//Forget failed logins of user
+	if err := ctl.guard.Unlock(c.Request.Context(), user.Email); err != nil {
+		c.Error(err)
+		return
+	}
+
+	//Response success
+	response.Success(c, http.StatusOK, "user.unlocked", toUserAdmin(user))
+}
+
+//Function to change role of user
+func (ctl *AdminController) AdminUpdateRole(c *gin.Context) {
+	//Convert json body to object
+	input := app.RoleRequest{}
+	err := bindJSON(c, &input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if !rbac.IsRole(input.Role) {
+		c.Error(apperror.Field("role", "oneof", "validation.invalid"))
+		return
+	}
+
+	//Check if user exist
+	user, err := ctl.users.FindByID(c.Param("userId"))
+	if err != nil {
+		c.Error(apperror.NotFound("user.not_found").With("id", c.Param("userId")))
+		return
+	}
+
+	//Update role
+	if err := ctl.users.SetRole(user.ID, input.Role); err != nil {
+		c.Error(err)
+		return
+	}
+	user.Role = input.Role
+
+	//Response success
+	response.Success(c, http.StatusOK, "user.role_updated", toUserAdmin(user))
+}
+
+//Function to delete any user
+func (ctl *AdminController) AdminDeleteUser(c *gin.Context) {
+	//Check if user exist
+	user, err := ctl.users.FindByID(c.Param("userId"))
+	if err != nil {
+		c.Error(apperror.NotFound("user.not_found").With("id", c.Param("userId")))
+		return
+	}
+
+	//Photos are removed with the user, their stored objects are not
+	photos, _ := ctl.photos.ListByUser(user.ID)
+
+	//Delete user
+	if err := ctl.users.Delete(user.ID); err != nil {
+		c.Error(err)
+		return
+	}
+	for _, photo := range photos {
+		deletePhotoObject(c, ctl.store, photo)
+	}
+
+	//Response success
+	response.Success(c, http.StatusOK, "user.deleted", nil)
+}
+
+//Function to delete any photo
+func (ctl *AdminController) AdminDeletePhoto(c *gin.Context) {
+	//Check if photo exist
+	photo, err := ctl.photos.FindByID(photoIDParam(c))
+	if err != nil {
+		c.Error(apperror.NotFound("photo.not_found").With("id", c.Param("photoId")))
+		return
+	}
+
+	//Delete photo from database
+	if err := ctl.photos.Delete(photo.ID); err != nil {
+		c.Error(err)
+		return
+	}
+	deletePhotoObject(c, ctl.store, photo)
+	promoteProfilePhoto(ctl.photos, photo)
+
+	//Response success
+	response.Success(c, http.StatusOK, "photo.deleted", nil)
+}
@@ -0,0 +1,21 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"task-vix-btpns/app/auth"
+)
+
+//Function to publish public keys used to verify JWT token
+func GetJWKS(c *gin.Context) {
+	jwks, err := auth.PublicJWKS()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	//JWKS is served as is so that standard JWT libraries can read it
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, jwks)
+}
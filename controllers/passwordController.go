@@ -0,0 +1,235 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"task-vix-btpns/app"
+	"task-vix-btpns/app/apperror"
+	"task-vix-btpns/app/auth"
+	"task-vix-btpns/app/i18n"
+	"task-vix-btpns/app/response"
+	"task-vix-btpns/helpers/policy"
+	"task-vix-btpns/helpers/validation"
+	"task-vix-btpns/lockout"
+	"task-vix-btpns/mailer"
+	"task-vix-btpns/models"
+	"task-vix-btpns/repository"
+)
+
+//Minimum time between two reset mails of one user
+const resetResendInterval = time.Minute
+
+//Handlers of password recovery endpoints
+type PasswordController struct {
+	users  repository.UserRepository
+	tokens repository.TokenRepository
+	mail   mailer.Mailer
+	guard  *lockout.Guard
+}
+
+//Function to create password recovery handlers
+func NewPasswordController(repos repository.Repositories, mail mailer.Mailer, guard *lockout.Guard) *PasswordController {
+	return &PasswordController{users: repos.Users, tokens: repos.Tokens, mail: mail, guard: guard}
+}
+
+//Function to build link of page where user chooses new password, the page posts token to reset endpoint
+func resetPasswordLink(token string) string {
+	link := os.Getenv("PASSWORD_RESET_URL")
+	if link == "" {
+		link = "http://localhost:8080/reset-password"
+	}
+	return link + "?token=" + url.QueryEscape(token)
+}
+
+//Function to get time of password change, kept in whole seconds like issue time of access token
+//so that token issued right after the change isn't refused and databases don't round it up
+func passwordChangeTime() time.Time {
+	return time.Now().Truncate(time.Second)
+}
+
+//Function to check new password of request field against password policy, current is empty for new account
+//and otherwise its current and previous passwords can't be used again
+func checkPasswordPolicy(users repository.UserRepository, field string, password string, username string, email string, current models.User) error {
+	account := policy.Account{Username: username, Email: email}
+	if current.ID != "" && policy.History() > 0 {
+		previous, err := users.PasswordHistory(current.ID, policy.History()-1)
+		if err != nil {
+			return err
+		}
+		account.Hashes = append([]string{current.Password}, previous...)
+	}
+	return policy.Check(field, password, account)
+}
+
+//Function to remember replaced password of user so that it isn't chosen again
+func recordPasswordChange(users repository.UserRepository, current models.User) error {
+	return users.AddPasswordHistory(current.ID, current.Password, policy.History()-1)
+}
+
+//Function to send reset link to email of user
+func (ctl *PasswordController) ForgotPassword(c *gin.Context) {
+	//Convert json body to object
+	input := app.ForgotPasswordRequest{}
+	err := bindJSON(c, &input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	err = validation.Struct("forgot", &input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	//Limit requests per address, failing lockout store doesn't block request
+	wait, err := ctl.guard.ResetRequest(c.Request.Context(), c.ClientIP())
+	if err != nil {
+		log.Printf("Counting reset request of %s error: %v", c.ClientIP(), err)
+	} else if wait > 0 {
+		c.Error(apperror.RateLimited("password.reset_limited", wait).With("seconds", strconv.Itoa(int(wait.Seconds()+0.5))))
+		return
+	}
+
+	//Unknown email and link sent too recently get the same response so that registered emails can't be discovered
+	user, err := ctl.users.FindByEmail(input.Email)
+	if err == repository.ErrNotFound {
+		response.Success(c, http.StatusOK, "password.reset_sent", nil)
+		return
+	} else if err != nil {
+		c.Error(err)
+		return
+	}
+	claimed, err := ctl.users.ClaimResetSend(user.ID, time.Now().Add(-resetResendInterval))
+	if err != nil {
+		c.Error(err)
+		return
+	} else if !claimed {
+		response.Success(c, http.StatusOK, "password.reset_sent", nil)
+		return
+	}
+
+	//Store hash of one-time token, earlier links of user stop working
+	token, hashed, err := auth.GenerateToken()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	reset_token := models.PasswordResetToken{}
+	reset_token.Init(user.ID, hashed, time.Now().Add(auth.ResetTokenTTL))
+	err = ctl.tokens.CreateResetToken(&reset_token)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	//Send reset link in language of request, mail is sent in background so that
+	//response time and failing mail server don't tell registered emails apart
+	lang := response.Language(c)
+	params := i18n.Params{
+		"username": user.Username,
+		"link":     resetPasswordLink(token),
+		"minutes":  strconv.Itoa(int(auth.ResetTokenTTL.Minutes())),
+	}
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: i18n.T(lang, "mail.reset_subject", params),
+		Body:    i18n.T(lang, "mail.reset_body", params),
+	}
+	go func() {
+		if err := ctl.mail.Send(context.Background(), msg); err != nil {
+			log.Printf("Sending reset link to user %s error: %v", user.ID, err)
+		}
+	}()
+
+	//Response success
+	response.Success(c, http.StatusOK, "password.reset_sent", nil)
+}
+
+//Function to set new password with token from reset link, every session of user is revoked
+func (ctl *PasswordController) ResetPassword(c *gin.Context) {
+	//Convert json body to object
+	input := app.ResetPasswordRequest{}
+	err := bindJSON(c, &input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	err = validation.Struct("reset", &input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	//Check if token exist and can still be used
+	reset_token, err := ctl.tokens.FindResetToken(auth.HashToken(input.Token))
+	if err == repository.ErrNotFound || (err == nil && !reset_token.IsUsable()) {
+		c.Error(apperror.Field("token", "invalid", "password.reset_invalid"))
+		return
+	} else if err != nil {
+		c.Error(err)
+		return
+	}
+
+	//Check new password before token is used, so that user can try another one
+	current, err := ctl.users.FindByID(reset_token.UserID)
+	if err == repository.ErrNotFound {
+		c.Error(apperror.Field("token", "invalid", "password.reset_invalid"))
+		return
+	} else if err != nil {
+		c.Error(err)
+		return
+	}
+	err = checkPasswordPolicy(ctl.users, "password", input.Password, current.Username, current.Email, current)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	//Hashing password, access tokens issued before the change are refused
+	changed_at := passwordChangeTime()
+	user := models.User{ID: reset_token.UserID, Password: input.Password, PasswordChangedAt: &changed_at}
+	err = user.HashPassword()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	//Mark token as used, concurrent request with same token loses
+	used, err := ctl.tokens.UseResetToken(reset_token.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	} else if !used {
+		c.Error(apperror.Field("token", "invalid", "password.reset_invalid"))
+		return
+	}
+
+	//Update password
+	err = ctl.users.Update(&user)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	err = recordPasswordChange(ctl.users, current)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	//Sessions opened with old password are closed
+	err = ctl.tokens.RevokeUserTokens(user.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	//Response success
+	response.Success(c, http.StatusOK, "password.reset_done", nil)
+}
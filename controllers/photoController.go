@@ -1,117 +1,305 @@
 package controllers
 
 import (
-	"io/ioutil"
+	"errors"
+	"log"
+	"mime/multipart"
 	"net/http"
-	"encoding/json"
+	"strconv"
 	"strings"
+	"time"
 	"github.com/gin-gonic/gin"
-	"github.com/jinzhu/gorm"
+	"github.com/google/uuid"
 	"task-vix-btpns/models"
 	"task-vix-btpns/app"
-	"task-vix-btpns/app/auth"
-	"task-vix-btpns/helpers/errorformat"
+	"task-vix-btpns/app/apperror"
+	"task-vix-btpns/app/response"
+	"task-vix-btpns/middlewares"
+	"task-vix-btpns/helpers/imaging"
+	"task-vix-btpns/helpers/pagination"
+	"task-vix-btpns/helpers/upload"
+	"task-vix-btpns/repository"
+	"task-vix-btpns/storage"
 )
 
-//GFunction to get photo profile
-func GetPhoto(c *gin.Context) {
-	//Create list photo
-	photos := []models.Photo{}
-
-	//Set database
-	db := c.MustGet("db").(*gorm.DB)
-	if err := db.Debug().Model(&models.Photo{}).Limit(100).Find(&photos).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"status":  "Error",
-			"message": "Photo not found",
-			"data":    nil,
-		})
+//Queue generating avatar variants of stored photos
+type AvatarQueue interface {
+	Enqueue(photoID int) bool
+}
+
+//Handlers of photo endpoints
+type PhotoController struct {
+	users   repository.UserRepository
+	photos  repository.PhotoRepository
+	store   storage.Storage
+	avatars AvatarQueue
+}
+
+//Function to create photo handlers
+func NewPhotoController(repos repository.Repositories, store storage.Storage, avatars AvatarQueue) *PhotoController {
+	return &PhotoController{users: repos.Users, photos: repos.Photos, store: store, avatars: avatars}
+}
+
+//Fields which can be used to sort photos
+var photoSortFields = map[string]pagination.Field{
+	"id":         {Column: "id", Kind: pagination.KindInt},
+	"created_at": {Column: "created_at", Kind: pagination.KindTime},
+	"title":      {Column: "title", Kind: pagination.KindString},
+}
+
+//Function to read avatar size and format query, size is 0 when original photo is wanted
+func readVariantQuery(c *gin.Context) (int, string, error) {
+	format := c.DefaultQuery("format", imaging.FormatJPEG)
+	if c.Query("size") == "" {
+		return 0, format, nil
+	}
+	size, _ := strconv.Atoi(c.Query("size"))
+	if !imaging.IsSize(size) || imaging.ContentType(format) == "" {
+		return 0, format, apperror.Validation("photo.variant")
+	}
+	return size, format, nil
+}
+
+//Function to read photo id from path, unknown id is 0 which matches no photo
+func photoIDParam(c *gin.Context) int {
+	id, _ := strconv.Atoi(c.Param("photoId"))
+	return id
+}
+
+//Function to get photo profile
+func (ctl *PhotoController) GetPhoto(c *gin.Context) {
+	ctl.listPhotos(c, c.Query("user_id"))
+}
+
+//Function to get one photo
+func (ctl *PhotoController) GetPhotoByID(c *gin.Context) {
+	//Read requested avatar size, original photo is returned when empty
+	size, format, err := readVariantQuery(c)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	//Init list photo
-	if len(photos) > 0 {
-		for i := range photos {
-			user := models.User{}
-			err := db.Model(&models.User{}).Where("id = ?", photos[i].UserID).Take(&user).Error
-
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{
-					"status":  "Error",
-					"message": err.Error(),
-					"data":    nil,
-				})
-				return
-			}
+	//Check if photo exist
+	photo, err := ctl.photos.FindByID(photoIDParam(c))
+	if err == repository.ErrNotFound {
+		c.Error(apperror.NotFound("photo.not_found").With("id", c.Param("photoId")))
+		return
+	} else if err != nil {
+		c.Error(err)
+		return
+	}
 
-			photos[i].Owner = app.Owner{
-				ID: user.ID, Username: user.Username, Email: user.Email,
-			}
-		}
+	if size != 0 {
+		photo.PhotoUrl = photo.VariantUrl(size, format)
 	}
 
 	//Return response
-	c.JSON(http.StatusOK, gin.H{
-		"status":  "Success",
-		"message": "Data retrieved successfully",
-		"data":    photos,
-	})
+	response.Success(c, http.StatusOK, "data.retrieved", photo)
 }
 
-//Function to create photo profile
-func CreatePhoto(c *gin.Context) {
-	//Set database
-	db := c.MustGet("db").(*gorm.DB)
-
-	//Get bearer token
-	tokenString := c.GetHeader("Authorization")
-	if tokenString == "" {
-		c.JSON(401, gin.H{"error": "T"})
+//Function to get every photo of a user
+func (ctl *PhotoController) GetUserPhotos(c *gin.Context) {
+	//Check if user exist
+	user, err := ctl.users.FindByID(c.Param("userId"))
+	if err != nil {
+		c.Error(apperror.NotFound("user.not_found").With("id", c.Param("userId")))
 		return
 	}
 
-	//Get user mail from JWT
-	email, err := auth.GetEmail(strings.Split(tokenString, "Bearer ")[1])
+	ctl.listPhotos(c, user.ID)
+}
+
+//Function to list photos page, filtered by owner when userID is not empty
+func (ctl *PhotoController) listPhotos(c *gin.Context, userID string) {
+	//Read requested avatar size, original photo is returned when empty
+	size, format, err := readVariantQuery(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"status":  "Error",
-			"message": err.Error(),
-			"data":    nil,
-		})
+		c.Error(err)
+		return
 	}
 
-	//Get user data from database
-	var user_has_login models.User
+	//Read page, default is newest photo first
+	page, err := pagination.Parse(c.Query("limit"), c.Query("sort"), c.Query("cursor"), photoSortFields, "-created_at")
+	if err != nil {
+		c.Error(err)
+		return
+	}
 
-	err = db.Debug().Where("email = ?", email).First(&user_has_login).Error
+	//Filter photos
+	filter := repository.PhotoFilter{UserID: userID, Title: c.Query("title")}
+	for _, bound := range []struct {
+		param string
+		value **time.Time
+		parse func(string) (time.Time, error)
+	}{{"created_from", &filter.CreatedFrom, pagination.ParseTime}, {"created_to", &filter.CreatedTo, pagination.ParseEndTime}} {
+		value := c.Query(bound.param)
+		if value == "" {
+			continue
+		}
+		created, err := bound.parse(value)
+		if err != nil {
+			c.Error(apperror.Field(bound.param, "datetime", "validation.datetime"))
+			return
+		}
+		*bound.value = &created
+	}
+
+	//Count every filtered photo when asked
+	meta := app.PageMeta{Limit: page.Limit}
+	if c.Query("include_total") == "true" {
+		total, err := ctl.photos.Count(filter)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		meta.Total = &total
+	}
+
+	photos, err := ctl.photos.List(filter, page)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"status":  "Error",
-			"message": "User with email " + email + " not found",
-			"data":    nil,
-		})
+		c.Error(err)
 		return
 	}
 
-	// Read body request
-	body, err := ioutil.ReadAll(c.Request.Body)
+	//Extra row means there is a next page
+	if len(photos) > page.Limit {
+		photos = photos[:page.Limit]
+		last := photos[len(photos)-1]
+		meta.NextCursor = page.Next(last.SortValue(page.Sort), last.ID)
+	}
+
+	//Use requested avatar size
+	if size != 0 {
+		for i := range photos {
+			photos[i].PhotoUrl = photos[i].VariantUrl(size, format)
+		}
+	}
+
+	//Return response
+	response.Page(c, http.StatusOK, "data.retrieved", photos, meta)
+}
+
+//Function to read photo from JSON body or multipart form, image is nil for JSON body
+func bindPhoto(c *gin.Context) (models.Photo, *upload.Image, error) {
+	photo := models.Photo{}
+
+	//Multipart form carries the image file itself
+	if c.ContentType() == "multipart/form-data" {
+		maxSize := upload.MaxSize()
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+(1<<20)) //Allow 1 MiB for other fields
+		if err := c.Request.ParseMultipartForm(maxSize); err != nil {
+			if bodyTooLarge(err) {
+				return photo, nil, apperror.New(apperror.CodePayloadTooLarge, "request.too_large")
+			}
+			return photo, nil, apperror.Wrap(apperror.CodeBadRequest, "request.malformed", err)
+		}
+
+		photo.Title = c.PostForm("title")
+		photo.Caption = c.PostForm("caption")
+		photo.IsProfile = c.PostForm("is_profile") == "true"
+
+		file, err := c.FormFile("photo")
+		if err != nil {
+			return photo, nil, apperror.Field("photo", "required", "validation.required")
+		}
+		image, err := upload.ReadImage(file, maxSize)
+		if err != nil {
+			return photo, nil, err
+		}
+		if err := image.Sanitize(); err != nil { //Drop EXIF, GPS and other metadata before storing
+			return photo, nil, apperror.Field("photo", "image", "photo.undecodable")
+		}
+		return photo, image, nil
+	}
+
+	//Convert json body to object, only fields of request are taken from body
+	input := app.PhotoRequest{}
+	err := bindJSON(c, &input)
+	photo.Title, photo.Caption, photo.PhotoUrl, photo.IsProfile = input.Title, input.Caption, input.PhotoUrl, input.IsProfile
+	return photo, nil, err
+}
+
+//Function to check if parsing form failed because body is over the limit, Go 1.18 has no
+//error type for MaxBytesReader so its message is matched
+func bodyTooLarge(err error) bool {
+	return errors.Is(err, multipart.ErrMessageTooLarge) || strings.Contains(err.Error(), "request body too large")
+}
+
+//Function to store uploaded image and fill photo url from stored object
+func (ctl *PhotoController) storePhoto(c *gin.Context, photo *models.Photo, image *upload.Image) error {
+	key := "photos/" + photo.UserID + "/" + uuid.New().String() + image.Extension
+	url, err := ctl.store.Put(c.Request.Context(), key, image.Reader(), image.Size(), image.ContentType)
 	if err != nil {
-		c.JSON(http.StatusUnprocessableEntity, gin.H{
-			"status":  "Error",
-			"message": err.Error(),
-			"data":    nil,
-		})
-	}
-	// log.Println(string(body))
-	//Convert json to object
-	input_photo := models.Photo{}
-	err = json.Unmarshal(body, &input_photo)
+		return err
+	}
+	photo.PhotoUrl = url
+	photo.StorageKey = key
+	photo.MetadataRemoved = "none"
+	if len(image.Removed) > 0 {
+		photo.MetadataRemoved = strings.Join(image.Removed, ",")
+	}
+	photo.VariantStatus = models.VariantPending
+	return nil
+}
+
+//Function to queue generation of avatar variants once photo is saved
+func (ctl *PhotoController) enqueueVariants(photo models.Photo) {
+	if photo.VariantStatus != models.VariantPending {
+		return
+	}
+	ctl.avatars.Enqueue(photo.ID)
+}
+
+//Function to remove stored object of photo, failure only leaves an orphan object
+func deletePhotoObject(c *gin.Context, store storage.Storage, photo models.Photo) {
+	if photo.StorageKey == "" {
+		return
+	}
+	keys := []string{photo.StorageKey}
+	for _, size := range imaging.Sizes {
+		for _, format := range imaging.Formats {
+			keys = append(keys, imaging.VariantKey(photo.StorageKey, size, format))
+		}
+	}
+	for _, key := range keys {
+		if err := store.Delete(c.Request.Context(), key); err != nil {
+			log.Printf("Deleting photo object %s error: %v", key, err)
+		}
+	}
+}
+
+//Function to remove stored object of photo once its url has been replaced
+func (ctl *PhotoController) releasePhotoObject(c *gin.Context, old_photo models.Photo, new_photo models.Photo) {
+	if old_photo.StorageKey == "" || new_photo.PhotoUrl == "" || new_photo.PhotoUrl == old_photo.PhotoUrl {
+		return
+	}
+	deletePhotoObject(c, ctl.store, old_photo)
+	ctl.photos.DeleteVariants(old_photo.ID)
+	if new_photo.StorageKey == "" { //Url given as JSON doesn't point to stored object
+		ctl.photos.ClearStorage(old_photo.ID)
+	}
+}
+
+//Function to make newest remaining photo the profile photo once profile photo is deleted
+func promoteProfilePhoto(photos repository.PhotoRepository, deleted models.Photo) {
+	if !deleted.IsProfile {
+		return
+	}
+	if next_photo, err := photos.FindNewest(deleted.UserID); err == nil {
+		photos.SetProfile(next_photo)
+	}
+}
+
+//Function to create photo profile
+func (ctl *PhotoController) CreatePhoto(c *gin.Context) {
+	//Get user data from AuthMiddleware
+	user_has_login := middlewares.CurrentUser(c)
+
+	//Read photo from request
+	input_photo, image, err := bindPhoto(c)
 	if err != nil {
-		c.JSON(http.StatusUnprocessableEntity, gin.H{
-			"status":  "Error",
-			"message": err.Error(),
-			"data":    nil,
-		})
+		c.Error(err)
 		return
 	}
 
@@ -125,247 +313,169 @@ func CreatePhoto(c *gin.Context) {
 	}
 	err = input_photo.Validate("upload") //Validate photo
 	if err != nil {
-		c.JSON(http.StatusUnprocessableEntity, gin.H{
-			"status":  "Error",
-			"message": err.Error(),
-			"data":    nil,
-		})
+		c.Error(err)
 		return
 	}
 
-	//Check if photo already exist
-	var old_photo models.Photo
-	err = db.Debug().Model(&models.Photo{}).Where("user_id = ?", user_has_login.ID).Find(&old_photo).Error
-	if err != nil {
-		if err.Error() == "Data not found" {
-			err = db.Debug().Create(&input_photo).Error //Create photo to database
-			if err != nil {
-				formattedError := errorformat.ErrorMessage(err.Error())
-				c.JSON(http.StatusInternalServerError, gin.H{
-					"status":  "Error",
-					"message": formattedError.Error(),
-					"data":    nil,
-				})
-				return
-			}
-			c.JSON(http.StatusOK, gin.H{
-				"status":  "Success",
-				"message": "Photo uploaded successfully",
-				"data":    input_photo,
-			})
+	//Store uploaded image
+	if image != nil {
+		if err := ctl.storePhoto(c, &input_photo, image); err != nil {
+			c.Error(err)
 			return
 		}
-		c.JSON(http.StatusUnprocessableEntity, gin.H{
-			"status":  "Error",
-			"message": err.Error(),
-			"data":    nil,
-		})
-		return
 	}
 
-	//Update photo with new data
-	input_photo.ID = old_photo.ID
-	err = db.Debug().Model(&old_photo).Updates(&input_photo).Error
+	//Create photo to database, photo is added to the gallery of the user
+	want_profile := input_photo.IsProfile
+	input_photo.IsProfile = false
+	err = ctl.photos.Create(&input_photo)
 	if err != nil {
-		formattedError := errorformat.ErrorMessage(err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"status":  "Error",
-			"message": formattedError.Error(),
-			"data":    nil,
-		})
+		deletePhotoObject(c, ctl.store, input_photo)
+		c.Error(err)
 		return
 	}
+	ctl.enqueueVariants(input_photo)
 
-	c.JSON(http.StatusOK, gin.H{
-		"status":  "Success",
-		"message": "Photo changed successfully",
-		"data":    input_photo,
-	}) //Return response
+	//First photo of user always becomes the profile photo
+	if !want_profile {
+		_, err := ctl.photos.FindProfile(user_has_login.ID)
+		want_profile = err == repository.ErrNotFound
+	}
+	if want_profile {
+		if err := ctl.photos.SetProfile(input_photo); err != nil {
+			c.Error(err)
+			return
+		}
+		input_photo.IsProfile = true
+	}
 
+	response.Success(c, http.StatusOK, "photo.uploaded", input_photo) //Return response
 }
 
 //Function to update photo profile
-func UpdatePhoto(c *gin.Context) {
-	//Set database
-	db := c.MustGet("db").(*gorm.DB)
-
-	//Get bearer token
-	tokenString := c.GetHeader("Authorization")
-	if tokenString == "" {
-		c.JSON(401, gin.H{"error": "Token not found"})
-		return
-	}
-
-	//Get user mail from JWT
-	email, err := auth.GetEmail(strings.Split(tokenString, "Bearer ")[1])
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"status":  "Error",
-			"message": err.Error(),
-			"data":    nil,
-		})
-	}
+func (ctl *PhotoController) UpdatePhoto(c *gin.Context) {
+	//Get user data from AuthMiddleware
+	user_has_login := middlewares.CurrentUser(c)
 
-	//Get user data from JWT
-	var user_has_login models.User
-
-	err = db.Debug().Where("email = ?", email).First(&user_has_login).Error
+	//Check if photo already exist
+	photo, err := ctl.photos.FindByID(photoIDParam(c))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"status":  "Error",
-			"message": "User with email " + email + " not found",
-			"data":    nil,
-		})
+		c.Error(apperror.NotFound("photo.not_found").With("id", c.Param("photoId")))
 		return
 	}
 
-	// Read body request
-	body, err := ioutil.ReadAll(c.Request.Body)
-	if err != nil {
-		c.JSON(http.StatusUnprocessableEntity, gin.H{
-			"status":  "Error",
-			"message": err.Error(),
-			"data":    nil,
-		})
-	}
-
-	//Convert json to object
-	photo_input := models.Photo{}
-	err = json.Unmarshal(body, &photo_input)
-	if err != nil {
-		c.JSON(http.StatusUnprocessableEntity, gin.H{
-			"status":  "Error",
-			"message": err.Error(),
-			"data":    nil,
-		})
+	//Validate user id
+	if user_has_login.ID != photo.UserID {
+		c.Error(apperror.Forbidden("photo.change_forbidden"))
 		return
 	}
 
-	//Validate photo
-	err = photo_input.Validate("change")
+	//Read photo from request
+	photo_input, image, err := bindPhoto(c)
 	if err != nil {
-		c.JSON(http.StatusUnprocessableEntity, gin.H{
-			"status":  "Error",
-			"message": err.Error(),
-			"data":    nil,
-		})
+		c.Error(err)
 		return
 	}
-
-	//Check if photo already exist
-	var photo models.Photo
-	if err := db.Debug().Where("id = ?", c.Param("photoId")).First(&photo).Error; err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"status":  "Error",
-			"message": "Photo with id " + c.Param("photoId") + " not found",
-			"data":    nil,
-		})
+	photo_input.ID = photo.ID
+	photo_input.UserID = photo.UserID
+	photo_input.IsProfile = false //Profile photo is switched by SetProfilePhoto only
+
+	//Validate photo, photo url of uploaded image is set once it is stored
+	action := "change"
+	if image != nil {
+		action = "upload"
+	}
+	err = photo_input.Validate(action)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	//Validate user id
-	if user_has_login.ID != photo.UserID {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"status":  "Error",
-			"message": "You can't change photo of another user",
-			"data":    nil,
-		})
-		return
+	//Store uploaded image
+	if image != nil {
+		if err := ctl.storePhoto(c, &photo_input, image); err != nil {
+			c.Error(err)
+			return
+		}
 	}
 
 	//Updating photo to database
-	err = db.Model(&photo).Updates(&photo_input).Error
+	replaced_photo := photo
+	err = ctl.photos.Update(&photo_input)
 	if err != nil {
-		formattedError := errorformat.ErrorMessage(err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"status":  "Error",
-			"message": formattedError.Error(),
-			"data":    nil,
-		})
+		deletePhotoObject(c, ctl.store, photo_input)
+		c.Error(err)
 		return
 	}
+	ctl.releasePhotoObject(c, replaced_photo, photo_input)
+	ctl.enqueueVariants(photo_input)
 
-	photo.Owner = app.Owner{
-		ID:       user_has_login.ID,
-		Username: user_has_login.Username,
-		Email:    user_has_login.Email,
+	//Reload photo with its owner and current variants
+	photo, err = ctl.photos.FindByID(photo.ID)
+	if err != nil {
+		c.Error(err)
+		return
 	}
 
 	//Response success
-	c.JSON(http.StatusOK, gin.H{
-		"status":  "Success",
-		"message": "Photo updated successfully",
-		"data":    photo,
-	})
+	response.Success(c, http.StatusOK, "photo.updated", photo)
 }
 
 //Function to delete photo
-func DeletePhoto(c *gin.Context) {
+func (ctl *PhotoController) DeletePhoto(c *gin.Context) {
 
-	//Set database
-	db := c.MustGet("db").(*gorm.DB)
+	//Get user data from AuthMiddleware
+	user_has_login := middlewares.CurrentUser(c)
 
-	//Get bearer token
-	tokenString := c.GetHeader("Authorization")
-	if tokenString == "" {
-		c.JSON(401, gin.H{"error": "Token not found"})
+	//Check if photo already exist
+	photo, err := ctl.photos.FindByID(photoIDParam(c))
+	if err != nil {
+		c.Error(apperror.NotFound("photo.not_found").With("id", c.Param("photoId")))
+		return
+	}
+
+	//Validate user id
+	if user_has_login.ID != photo.UserID {
+		c.Error(apperror.Forbidden("photo.delete_forbidden"))
 		return
 	}
 
-	//Get user mail from JWT
-	email, err := auth.GetEmail(strings.Split(tokenString, "Bearer ")[1])
+	//Delete photo from database
+	err = ctl.photos.Delete(photo.ID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"status":  "Error",
-			"message": err.Error(),
-			"data":    nil,
-		})
-	}
-
-	//Get user data from JWT
-	var user_has_login models.User
-	if err := db.Debug().Where("email = ?", email).First(&user_has_login).Error; err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"status":  "Error",
-			"message": "User with email " + email + " not found",
-			"data":    nil})
+		c.Error(err)
 		return
 	}
+	deletePhotoObject(c, ctl.store, photo)
+	promoteProfilePhoto(ctl.photos, photo)
+
+	response.Success(c, http.StatusOK, "photo.deleted", nil) //Return response
+}
+
+//Function to make photo the active profile photo
+func (ctl *PhotoController) SetProfilePhoto(c *gin.Context) {
+	//Get user data from AuthMiddleware
+	user_has_login := middlewares.CurrentUser(c)
 
 	//Check if photo already exist
-	var photo models.Photo
-	if err := db.Debug().Where("id = ?", c.Param("photoId")).First(&photo).Error; err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"status":  "Error",
-			"message": "Photo not found",
-			"data":    nil,
-		})
+	photo, err := ctl.photos.FindByID(photoIDParam(c))
+	if err != nil {
+		c.Error(apperror.NotFound("photo.not_found").With("id", c.Param("photoId")))
 		return
 	}
 
 	//Validate user id
 	if user_has_login.ID != photo.UserID {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"status":  "Error",
-			"message": "You can't delete photo of another user",
-			"data":    nil,
-		})
+		c.Error(apperror.Forbidden("photo.profile_forbidden"))
 		return
 	}
 
-	//Delete photo from database
-	err = db.Debug().Delete(&photo).Error
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"status":  "Error",
-			"message": err.Error(),
-			"data":    nil,
-		})
+	if err := ctl.photos.SetProfile(photo); err != nil {
+		c.Error(err)
 		return
 	}
+	photo.IsProfile = true
 
-	c.JSON(http.StatusOK, gin.H{
-		"status":  "Success",
-		"message": "Photo deleted successfully",
-		"data":    nil}) //Return response
+	//Response success
+	response.Success(c, http.StatusOK, "photo.profile_changed", photo)
 }
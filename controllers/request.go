@@ -0,0 +1,27 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+
+	"github.com/gin-gonic/gin"
+	"task-vix-btpns/app/apperror"
+)
+
+//Function to read JSON body into input, field with wrong type is reported by its name
+func bindJSON(c *gin.Context, input interface{}) error {
+	body, err := ioutil.ReadAll(c.Request.Body)
+	if err != nil {
+		return apperror.Validation("request.unreadable")
+	}
+
+	err = json.Unmarshal(body, input)
+	var type_err *json.UnmarshalTypeError
+	if errors.As(err, &type_err) && type_err.Field != "" {
+		return apperror.Field(type_err.Field, "type", "validation.type").With("param", type_err.Type.String())
+	} else if err != nil {
+		return apperror.Validation("request.invalid")
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package controllers
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"task-vix-btpns/app"
+	"task-vix-btpns/app/apperror"
+	"task-vix-btpns/app/auth"
+	"task-vix-btpns/app/response"
+	"task-vix-btpns/middlewares"
+	"task-vix-btpns/models"
+	"task-vix-btpns/repository"
+)
+
+//Handlers of token endpoints
+type TokenController struct {
+	users  repository.UserRepository
+	tokens repository.TokenRepository
+}
+
+//Function to create token handlers
+func NewTokenController(repos repository.Repositories) *TokenController {
+	return &TokenController{users: repos.Users, tokens: repos.Tokens}
+}
+
+//Function to build new refresh token, only its hash is stored
+func newRefreshToken(userID string, familyID string) (models.RefreshToken, string, error) {
+	token, hashed, err := auth.GenerateToken()
+	if err != nil {
+		return models.RefreshToken{}, "", err
+	}
+
+	refresh_token := models.RefreshToken{}
+	refresh_token.Init(userID, familyID, hashed, time.Now().Add(auth.RefreshTokenTTL))
+	return refresh_token, token, nil
+}
+
+//Function to issue new refresh token and store its hash
+func issueRefreshToken(tokens repository.TokenRepository, userID string, familyID string) (models.RefreshToken, string, error) {
+	refresh_token, token, err := newRefreshToken(userID, familyID)
+	if err != nil {
+		return models.RefreshToken{}, "", err
+	}
+
+	err = tokens.CreateRefreshToken(&refresh_token)
+	if err != nil {
+		return models.RefreshToken{}, "", err
+	}
+	return refresh_token, token, nil
+}
+
+//Function to exchange refresh token for new token pair
+func (ctl *TokenController) RefreshToken(c *gin.Context) {
+	//Convert json body to object
+	input := app.RefreshRequest{}
+	err := bindJSON(c, &input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if input.RefreshToken == "" {
+		c.Error(apperror.Field("refresh_token", "required", "validation.required"))
+		return
+	}
+
+	//Check if refresh token exist
+	old_token, err := ctl.tokens.FindRefreshToken(auth.HashToken(input.RefreshToken))
+	if err != nil {
+		c.Error(apperror.Unauthorized("auth.refresh_invalid"))
+		return
+	}
+
+	//Reuse of a rotated token means it was stolen, revoke the whole family
+	if old_token.IsRevoked() {
+		ctl.tokens.RevokeFamily(old_token.FamilyID)
+		c.Error(apperror.Unauthorized("auth.refresh_revoked"))
+		return
+	}
+
+	if old_token.IsExpired() {
+		c.Error(apperror.Unauthorized("auth.refresh_expired"))
+		return
+	}
+
+	//Get user data from database
+	user, err := ctl.users.FindByID(old_token.UserID)
+	if err != nil {
+		c.Error(apperror.NotFound("user.not_found").With("id", old_token.UserID))
+		return
+	}
+
+	//Suspended user can't refresh token
+	if user.IsSuspended() {
+		ctl.tokens.RevokeFamily(old_token.FamilyID)
+		c.Error(apperror.Forbidden("auth.suspended"))
+		return
+	}
+
+	//Rotate refresh token
+	new_token, token, err := newRefreshToken(user.ID, old_token.FamilyID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	rotated, err := ctl.tokens.RotateRefreshToken(old_token, &new_token)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	//Token revoked since it was read has been used by another request, it is reuse as well
+	if !rotated {
+		ctl.tokens.RevokeFamily(old_token.FamilyID)
+		c.Error(apperror.Unauthorized("auth.refresh_revoked"))
+		return
+	}
+
+	//Generate new access token
+	access_token, err := auth.GenerateJWT(user.ID, user.Username, user.Role)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	//Return response
+	response.Success(c, http.StatusOK, "token.refreshed", app.TokenPair{Token: access_token, RefreshToken: token})
+}
+
+//Function to logout user, revoking access token and refresh token
+func (ctl *TokenController) Logout(c *gin.Context) {
+	//Get claims from AuthMiddleware
+	claims := middlewares.CurrentClaims(c)
+
+	//Read body form, refresh token is optional
+	input := app.RefreshRequest{}
+	body, err := ioutil.ReadAll(c.Request.Body)
+	if err == nil && len(body) > 0 {
+		json.Unmarshal(body, &input)
+	}
+
+	//Revoke access token
+	revoked := models.RevokedToken{JTI: claims.Id, ExpiresAt: time.Unix(claims.ExpiresAt, 0)}
+	err = ctl.tokens.RevokeAccessToken(&revoked)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	//Revoke refresh token family owned by the user
+	if input.RefreshToken != "" {
+		refresh_token, err := ctl.tokens.FindRefreshToken(auth.HashToken(input.RefreshToken))
+		if err == nil {
+			if claims.Subject == refresh_token.UserID {
+				ctl.tokens.RevokeFamily(refresh_token.FamilyID)
+			}
+		}
+	}
+
+	//Response success
+	response.Success(c, http.StatusOK, "user.logged_out", nil)
+}
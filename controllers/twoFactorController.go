@@ -0,0 +1,282 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"task-vix-btpns/app"
+	"task-vix-btpns/app/apperror"
+	"task-vix-btpns/app/auth"
+	"task-vix-btpns/app/response"
+	"task-vix-btpns/helpers/validation"
+	"task-vix-btpns/lockout"
+	"task-vix-btpns/middlewares"
+	"task-vix-btpns/models"
+	"task-vix-btpns/repository"
+)
+
+//Handlers of two-factor authentication endpoints
+type TwoFactorController struct {
+	users  repository.UserRepository
+	photos repository.PhotoRepository
+	tokens repository.TokenRepository
+	guard  *lockout.Guard
+}
+
+//Function to create two-factor authentication handlers
+func NewTwoFactorController(repos repository.Repositories, guard *lockout.Guard) *TwoFactorController {
+	return &TwoFactorController{users: repos.Users, photos: repos.Photos, tokens: repos.Tokens, guard: guard}
+}
+
+//Function to remove spaces user may type inside code
+func normalizeCode(code string) string {
+	return strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+}
+
+//Function to check TOTP or recovery code of user, each code is accepted only once
+func checkSecondFactor(users repository.UserRepository, user models.User, code string) (bool, error) {
+	code = normalizeCode(code)
+	if step, ok := auth.ValidateTOTP(user.TOTPSecret, code, time.Now()); ok {
+		return users.UseTOTPStep(user.ID, step)
+	}
+	used, err := users.UseRecoveryCode(user.ID, auth.HashRecoveryCode(user.ID, code))
+	if used || err != nil {
+		return used, err
+	}
+	//Codes given before they were salted still work until two-factor is enabled again
+	return users.UseRecoveryCode(user.ID, auth.HashLegacyRecoveryCode(code))
+}
+
+//Function to exchange challenge token of login and TOTP or recovery code for access token
+func (ctl *TwoFactorController) VerifyLogin(c *gin.Context) {
+	//Convert json body to object
+	input := app.TwoFactorLoginRequest{}
+	err := bindJSON(c, &input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	err = validation.Struct("challenge", &input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	//Challenge proves that password was accepted a moment ago
+	userID, err := auth.ParseChallengeToken(input.ChallengeToken)
+	if errors.Is(err, auth.ErrTokenExpired) {
+		c.Error(apperror.Unauthorized("auth.challenge_expired"))
+		return
+	} else if err != nil {
+		c.Error(apperror.Wrap(apperror.CodeUnauthorized, "auth.challenge_invalid", err))
+		return
+	}
+
+	//Check if user still exist and uses two-factor authentication
+	user, err := ctl.users.FindByID(userID)
+	if err != nil || !user.HasTwoFactor() {
+		c.Error(apperror.Unauthorized("auth.challenge_invalid"))
+		return
+	}
+	if user.IsSuspended() {
+		c.Error(apperror.Forbidden("auth.suspended"))
+		return
+	}
+
+	//Wrong codes count as failed logins, so codes can't be guessed
+	err = checkLockout(c, ctl.guard, user.Email)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	//Verify code
+	accepted, err := checkSecondFactor(ctl.users, user, input.Code)
+	if err != nil {
+		c.Error(err)
+		return
+	} else if !accepted {
+		if err := recordLoginFailure(c, ctl.guard, user.Email); err != nil {
+			c.Error(err)
+			return
+		}
+		c.Error(apperror.Unauthorized("totp.code_invalid"))
+		return
+	}
+
+	//Generate tokens when success login
+	data, err := newLoginData(ctl.photos, ctl.tokens, user)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	recordLoginSuccess(c, ctl.guard, user.Email)
+
+	//Return response
+	response.Success(c, http.StatusOK, "user.logged_in", data)
+}
+
+//Function to start two-factor setup, secret is confirmed by Enable before login asks for codes
+func (ctl *TwoFactorController) Setup(c *gin.Context) {
+	//Convert json body to object
+	input := app.TwoFactorSetupRequest{}
+	err := bindJSON(c, &input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	err = validation.Struct("setup", &input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	//Get user data from AuthMiddleware
+	user_has_login := middlewares.CurrentUser(c)
+	if user_has_login.HasTwoFactor() {
+		c.Error(apperror.Conflict("totp.already_enabled"))
+		return
+	}
+
+	//Verify password, failures count toward login lockout
+	err = reauthenticate(c, ctl.guard, user_has_login, "current_password", input.CurrentPassword)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	//New secret replaces the one of unfinished setup
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	saved, err := ctl.users.SetTOTPSecret(user_has_login.ID, secret)
+	if err != nil {
+		c.Error(err)
+		return
+	} else if !saved {
+		c.Error(apperror.Conflict("totp.already_enabled"))
+		return
+	}
+
+	data := app.TwoFactorSetup{Secret: secret, OtpauthURI: auth.TOTPURI(secret, user_has_login.Email)}
+
+	//Response success
+	response.Success(c, http.StatusOK, "totp.setup_started", data)
+}
+
+//Function to enable two-factor authentication once user proves the authenticator app has the secret
+func (ctl *TwoFactorController) Enable(c *gin.Context) {
+	//Convert json body to object
+	input := app.TwoFactorEnableRequest{}
+	err := bindJSON(c, &input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	err = validation.Struct("enable", &input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	//Get user data from AuthMiddleware
+	user_has_login := middlewares.CurrentUser(c)
+	if user_has_login.HasTwoFactor() {
+		c.Error(apperror.Conflict("totp.already_enabled"))
+		return
+	} else if user_has_login.TOTPSecret == "" {
+		c.Error(apperror.Conflict("totp.setup_required"))
+		return
+	}
+
+	//Verify password, failures count toward login lockout
+	err = reauthenticate(c, ctl.guard, user_has_login, "current_password", input.CurrentPassword)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	//Verify code made from the new secret
+	step, ok := auth.ValidateTOTP(user_has_login.TOTPSecret, normalizeCode(input.Code), time.Now())
+	if !ok {
+		c.Error(apperror.Field("code", "invalid", "totp.code_invalid"))
+		return
+	}
+
+	//Recovery codes are shown only now, only their hashes are stored
+	codes, hashed, err := auth.GenerateRecoveryCodes(user_has_login.ID, auth.RecoveryCodeCount)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	recovery_codes := make([]models.RecoveryCode, len(hashed))
+	for i := range hashed {
+		recovery_codes[i].Init(user_has_login.ID, hashed[i])
+	}
+	enabled, err := ctl.users.EnableTOTP(user_has_login.ID, step, recovery_codes)
+	if err != nil {
+		c.Error(err)
+		return
+	} else if !enabled {
+		c.Error(apperror.Conflict("totp.already_enabled"))
+		return
+	}
+
+	//Response success
+	response.Success(c, http.StatusOK, "totp.enabled", app.RecoveryCodes{RecoveryCodes: codes})
+}
+
+//Function to disable two-factor authentication, password and a code are both required
+func (ctl *TwoFactorController) Disable(c *gin.Context) {
+	//Convert json body to object
+	input := app.TwoFactorDisableRequest{}
+	err := bindJSON(c, &input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	err = validation.Struct("disable", &input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	//Get user data from AuthMiddleware
+	user_has_login := middlewares.CurrentUser(c)
+	if !user_has_login.HasTwoFactor() {
+		c.Error(apperror.Conflict("totp.not_enabled"))
+		return
+	}
+
+	//Verify password, failures count toward login lockout
+	err = reauthenticate(c, ctl.guard, user_has_login, "password", input.Password)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	//Verify code
+	accepted, err := checkSecondFactor(ctl.users, user_has_login, input.Code)
+	if err != nil {
+		c.Error(err)
+		return
+	} else if !accepted {
+		c.Error(apperror.Field("code", "invalid", "totp.code_invalid"))
+		return
+	}
+
+	//Remove secret and recovery codes
+	err = ctl.users.DisableTOTP(user_has_login.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	//Response success
+	response.Success(c, http.StatusOK, "totp.disabled", nil)
+}
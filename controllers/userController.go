@@ -1,302 +1,390 @@
 package controllers
 
 import (
-	"encoding/json"
-	"io/ioutil"
 	"log"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/jinzhu/gorm"
-	"golang.org/x/crypto/bcrypt"
 	"task-vix-btpns/models"
 	"task-vix-btpns/app"
+	"task-vix-btpns/app/apperror"
 	"task-vix-btpns/app/auth"
-	"task-vix-btpns/helpers/errorformat"
+	"task-vix-btpns/app/response"
 	"task-vix-btpns/helpers/hash"
+	"task-vix-btpns/lockout"
+	"task-vix-btpns/mailer"
+	"task-vix-btpns/middlewares"
+	"task-vix-btpns/repository"
+	"task-vix-btpns/storage"
 )
 
-//Function to be used for user login
-func Login(c *gin.Context) {
-	//Set database
-	db := c.MustGet("db").(*gorm.DB)
+//Handlers of user endpoints
+type UserController struct {
+	users  repository.UserRepository
+	photos repository.PhotoRepository
+	tokens repository.TokenRepository
+	store  storage.Storage
+	mail   mailer.Mailer
+	guard  *lockout.Guard
+}
+
+//Function to create user handlers
+func NewUserController(repos repository.Repositories, store storage.Storage, mail mailer.Mailer, guard *lockout.Guard) *UserController {
+	return &UserController{users: repos.Users, photos: repos.Photos, tokens: repos.Tokens, store: store, mail: mail, guard: guard}
+}
+
+//Function to build error of login refused while account or address is locked
+func lockedError(locked time.Duration) error {
+	return apperror.RateLimited("auth.locked", locked).With("seconds", strconv.Itoa(int(math.Ceil(locked.Seconds()))))
+}
 
-	//Read body form
-	body, err := ioutil.ReadAll(c.Request.Body)
+//Function to refuse login while account or address is locked, failing lockout store doesn't block login
+func checkLockout(c *gin.Context, guard *lockout.Guard, account string) error {
+	locked, err := guard.Check(c.Request.Context(), account, c.ClientIP())
 	if err != nil {
-		c.JSON(http.StatusUnprocessableEntity, gin.H{
-			"status":  "Error",
-			"message": err.Error(),
-			"data":    nil,
-		})
-		return
+		log.Printf("Checking login lockout of %s error: %v", account, err)
+		return nil
+	}
+	if locked > 0 {
+		return lockedError(locked)
+	}
+	return nil
+}
+
+//Function to count failed login, returns error to respond with when the failure locked account or address
+func recordLoginFailure(c *gin.Context, guard *lockout.Guard, account string) error {
+	locked, err := guard.Fail(c.Request.Context(), account, c.ClientIP())
+	if err != nil {
+		log.Printf("Recording failed login of %s error: %v", account, err)
+		return nil
+	}
+	if locked > 0 {
+		return lockedError(locked)
+	}
+	return nil
+}
+
+//Function to confirm password of user who has login before sensitive change, failures count toward login lockout
+func reauthenticate(c *gin.Context, guard *lockout.Guard, user models.User, field string, password string) error {
+	err := checkLockout(c, guard, user.Email)
+	if err != nil {
+		return err
+	}
+	err = user.CheckPassword(password)
+	if err != nil {
+		if err := recordLoginFailure(c, guard, user.Email); err != nil {
+			return err
+		}
+		return apperror.Field(field, "invalid", "auth.wrong_password")
+	}
+	return nil
+}
+
+//Function to forget failed logins of account once user is fully logged in
+func recordLoginSuccess(c *gin.Context, guard *lockout.Guard, account string) {
+	err := guard.Succeed(c.Request.Context(), account)
+	if err != nil {
+		log.Printf("Resetting failed logins of %s error: %v", account, err)
 	}
+}
 
-	//Convert json to object
-	user_model := models.User{}
-	err = json.Unmarshal(body, &user_model)
+//Function to be used for user login
+func (ctl *UserController) Login(c *gin.Context) {
+	//Convert json body to object
+	input := app.LoginRequest{}
+	err := bindJSON(c, &input)
 	if err != nil {
-		c.JSON(http.StatusUnprocessableEntity, gin.H{
-			"status":  "Error",
-			"message": err.Error(),
-			"data":    nil,
-		})
+		c.Error(err)
 		return
 	}
+	user_model := models.User{Email: input.Email, Password: input.Password}
 
 	//Init user
 	user_model.Init()
 	err = user_model.Validate("login")
 	if err != nil {
-		c.JSON(http.StatusUnprocessableEntity, gin.H{
-			"status":  "Error",
-			"message": err.Error(),
-			"data":    nil,
-		})
+		c.Error(err)
 		return
 	}
 
-	//Check if user exist
-	var user_login app.UserLogin
+	//Locked account or address is refused before password is checked
+	err = checkLockout(c, ctl.guard, user_model.Email)
+	if err != nil {
+		c.Error(err)
+		return
+	}
 
-	err = db.Debug().Table("users").Select("*").Joins("LEFT JOIN photos ON photos.user_id = users.id").
-		Where("users.email = ?", user_model.Email).Find(&user_login).Error
+	//Check if user exist
+	user_login, err := ctl.users.FindByEmail(user_model.Email)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"status":  "Error",
-			"message": "User with email " + user_model.Email + " not found",
-			"data":    nil,
-		})
+		if err := recordLoginFailure(c, ctl.guard, user_model.Email); err != nil {
+			c.Error(err)
+			return
+		}
+		c.Error(apperror.Unauthorized("auth.email_not_found").With("email", user_model.Email))
 		return
 	}
 
 	//Verify password
 	err = hash.CheckPasswordHash(user_login.Password, user_model.Password)
-	if err != nil && err == bcrypt.ErrMismatchedHashAndPassword {
-		formattedError := errorformat.ErrorMessage(err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{
-			"status":  "Error",
-			"message": formattedError.Error(),
-			"data":    nil,
-		})
+	if err != nil {
+		if err := recordLoginFailure(c, ctl.guard, user_model.Email); err != nil {
+			c.Error(err)
+			return
+		}
+		c.Error(apperror.Unauthorized("auth.wrong_password"))
 		return
 	}
+	ctl.rehashPassword(user_login, user_model.Password)
 
-	//Generate token when success login
-	token, err := auth.GenerateJWT(user_login.Email, user_login.Username)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"status":  "Error",
-			"message": err.Error(),
-			"data":    nil,
-		})
+	//Suspended user can't login
+	if user_login.SuspendedAt != nil {
+		c.Error(apperror.Forbidden("auth.suspended"))
+		return
+	}
+
+	//Second step is required when user has enabled two-factor authentication, failures are kept until it passes
+	if user_login.HasTwoFactor() {
+		challenge_token, err := auth.GenerateChallengeToken(user_login.ID)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		data := app.LoginChallenge{TwoFactorRequired: true, ChallengeToken: challenge_token, ExpiresIn: int(auth.ChallengeTTL.Seconds())}
+		response.Success(c, http.StatusOK, "auth.two_factor_required", data)
 		return
 	}
 
-	data := app.UserData{
-		ID: user_login.ID, Username: user_login.Username, Email: user_login.Email, Token: token,
-		Photos: app.Photo{Title: user_login.Title, Caption: user_login.Caption, PhotoUrl: user_login.PhotoUrl},
+	//Generate tokens when success login
+	data, err := newLoginData(ctl.photos, ctl.tokens, user_login)
+	if err != nil {
+		c.Error(err)
+		return
 	}
+	recordLoginSuccess(c, ctl.guard, user_login.Email)
 
 	//Return response
-	c.JSON(http.StatusUnprocessableEntity, gin.H{
-		"status":  "Success",
-		"message": "Login successfully",
-		"data":    data,
-	})
+	response.Success(c, http.StatusOK, "user.logged_in", data)
 }
 
-//Function to register user
-func CreateUser(c *gin.Context) {
-	//set database
-	db := c.MustGet("db").(*gorm.DB)
+//Function to upgrade hash of accepted password to the configured algorithm, failure doesn't stop login
+func (ctl *UserController) rehashPassword(user models.User, password string) {
+	if !user.NeedsRehash() {
+		return
+	}
+	rehashed := models.User{Password: password}
+	err := rehashed.HashPassword()
+	if err == nil {
+		err = ctl.users.RehashPassword(user.ID, user.Password, rehashed.Password)
+	}
+	if err != nil {
+		log.Printf("Rehashing password of %s error: %v", user.ID, err)
+	}
+}
 
-	// read body form
-	body, err := ioutil.ReadAll(c.Request.Body)
+//Function to open new session of user whose credentials were accepted
+func newLoginData(photos repository.PhotoRepository, tokens repository.TokenRepository, user models.User) (app.UserData, error) {
+	//Generate access token
+	token, err := auth.GenerateJWT(user.ID, user.Username, user.Role)
 	if err != nil {
-		c.JSON(http.StatusUnprocessableEntity, gin.H{
-			"status":  "Error",
-			"message": err.Error(),
-			"data":    nil,
-		})
+		return app.UserData{}, err
 	}
 
-	//Convert json to object
-	user_model := models.User{}
-	err = json.Unmarshal(body, &user_model)
+	//Generate refresh token for new session
+	_, refresh_token, err := issueRefreshToken(tokens, user.ID, "")
 	if err != nil {
-		c.JSON(http.StatusUnprocessableEntity, gin.H{
-			"status":  "Error",
-			"message": err.Error(),
-			"data":    nil,
-		})
+		return app.UserData{}, err
+	}
+
+	//Profile photo is empty when user has no photo
+	profile_photo, _ := photos.FindProfile(user.ID)
+
+	return app.UserData{
+		ID: user.ID, Username: user.Username, Email: user.Email, Token: token, RefreshToken: refresh_token,
+		Photos: app.Photo{Title: profile_photo.Title, Caption: profile_photo.Caption, PhotoUrl: profile_photo.PhotoUrl},
+	}, nil
+}
+
+//Function to register user
+func (ctl *UserController) CreateUser(c *gin.Context) {
+	//Convert json body to object, only fields of request are taken from body
+	input := app.RegisterRequest{}
+	err := bindJSON(c, &input)
+	if err != nil {
+		c.Error(err)
 		return
 	}
+	user_model := models.User{Username: input.Username, Email: input.Email, Password: input.Password}
 
 	user_model.Init() //Inisialize user
 
-	err = user_model.Validate("update") //Validate user
+	//Validate user and check password policy, every failing field is reported at once
+	err = apperror.JoinValidation(
+		user_model.Validate("register"),
+		checkPasswordPolicy(ctl.users, "password", user_model.Password, user_model.Username, user_model.Email, models.User{}),
+	)
 	if err != nil {
-		c.JSON(http.StatusUnprocessableEntity, gin.H{
-			"status":  "Error",
-			"message": err.Error(),
-			"data":    nil,
-		})
+		c.Error(err)
 		return
 	}
 
 	err = user_model.HashPassword() //Hash password
 	if err != nil {
-		log.Fatal(err)
+		c.Error(err)
+		return
 	}
 
-	err = db.Debug().Create(&user_model).Error //Create user to database
+	err = ctl.users.Create(&user_model) //Create user to database
 	if err != nil {
-		formattedError := errorformat.ErrorMessage(err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"status":  "Error",
-			"message": formattedError.Error(),
-			"data":    nil,
-		})
+		c.Error(err)
 		return
 	}
 
-	data := app.UserRegister{ //data to be used for response
-		ID:        user_model.ID,
-		Username:  user_model.Username,
-		Email:     user_model.Email,
-		CreatedAt: user_model.CreatedAt,
-		UpdatedAt: user_model.UpdatedAt,
+	//New account is unverified until link sent to its email is opened, user may ask the link again
+	err = sendVerificationMail(c, ctl.users, ctl.mail, user_model)
+	if err != nil {
+		log.Printf("Sending verification mail to %s error: %v", user_model.Email, err)
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"status":  "Success",
-		"message": "User registered succesfully",
-		"data":    data,
-	}) //Response success
+	response.Success(c, http.StatusOK, "user.registered", toUserRegister(user_model)) //Response success
+}
+
+//Function to check if user who has login may manage the target user
+func canManageUser(user_has_login models.User, userID string) bool {
+	return user_has_login.ID == userID || user_has_login.IsAdmin()
 }
 
 //Function to update user
-func UpdateUser(c *gin.Context) {
+func (ctl *UserController) UpdateUser(c *gin.Context) {
 
-	//Set database
-	db := c.MustGet("db").(*gorm.DB)
+	//Get user data from AuthMiddleware
+	user_has_login := middlewares.CurrentUser(c)
 
-	//Check if user exist
-	var user models.User
-	err := db.Debug().Where("id = ?", c.Param("userId")).First(&user).Error
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"status":  "Error",
-			"message": "User with id " + c.Param("userId") + " not found",
-			"data":    nil,
-		})
+	//Validate user id, only admin can update another user
+	if !canManageUser(user_has_login, c.Param("userId")) {
+		c.Error(apperror.Forbidden("user.update_forbidden"))
 		return
 	}
 
-	//Read body form
-	body, err := ioutil.ReadAll(c.Request.Body)
+	//Check if user exist
+	user, err := ctl.users.FindByID(c.Param("userId"))
 	if err != nil {
-		c.JSON(http.StatusUnprocessableEntity, gin.H{
-			"status":  "Error",
-			"message": err.Error(),
-			"data":    nil,
-		})
+		c.Error(apperror.NotFound("user.not_found").With("id", c.Param("userId")))
+		return
 	}
 
-	//Convert json to object
-	user_model := models.User{}
-
-	user_model.ID = user.ID
-	err = json.Unmarshal(body, &user_model)
+	//Convert json body to object, only fields of request are taken from body
+	input := app.UpdateUserRequest{}
+	err = bindJSON(c, &input)
 	if err != nil {
-		c.JSON(http.StatusUnprocessableEntity, gin.H{
-			"status":  "Error",
-			"message": err.Error(),
-			"data":    nil,
-		})
+		c.Error(err)
 		return
 	}
+	user_model := models.User{ID: user.ID, Username: input.Username}
 
 	//Validate user
 	err = user_model.Validate("update")
 	if err != nil {
-		c.JSON(http.StatusUnprocessableEntity, gin.H{
-			"status":  "Error",
-			"message": err.Error(),
-			"data":    nil,
-		})
+		c.Error(err)
 		return
 	}
 
-	//Hashing password
-	err = user_model.HashPassword()
+	//Update user, email and password are changed through their own endpoints with current password
+	err = ctl.users.Update(&user_model)
 	if err != nil {
-		log.Fatal(err)
+		c.Error(err)
+		return
 	}
-
-	//Update user
-	err = db.Debug().Model(&user).Updates(&user_model).Error
+	user, err = ctl.users.FindByID(user.ID)
 	if err != nil {
-		formattedError := errorformat.ErrorMessage(err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"status":  "Error",
-			"message": formattedError.Error(),
-			"data":    nil,
-		})
+		c.Error(err)
 		return
 	}
 
-	data := app.UserRegister{ //data to be used for response
-		ID:        user_model.ID,
-		Username:  user_model.Username,
-		Email:     user_model.Email,
-		CreatedAt: user_model.CreatedAt,
-		UpdatedAt: user_model.UpdatedAt,
-	}
-
 	//Response success
-	c.JSON(http.StatusOK, gin.H{
-		"status":  "Error",
-		"message": "User updated succesfully",
-		"data":    data,
-	})
+	response.Success(c, http.StatusOK, "user.updated", toUserRegister(user))
 }
 
 //Function to delete user
-func DeleteUser(c *gin.Context) {
+func (ctl *UserController) DeleteUser(c *gin.Context) {
 
-	//Set database
-	db := c.MustGet("db").(*gorm.DB)
+	//Get user data from AuthMiddleware
+	user_has_login := middlewares.CurrentUser(c)
 
-	//Check if user exist
-	var user models.User
+	//Validate user id, only admin can delete another user
+	if !canManageUser(user_has_login, c.Param("userId")) {
+		c.Error(apperror.Forbidden("user.delete_forbidden"))
+		return
+	}
 
-	err := db.Debug().Where("id = ?", c.Param("userId")).First(&user).Error
+	//Check if user exist
+	user, err := ctl.users.FindByID(c.Param("userId"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"status":  "Error",
-			"message": "User with id " + c.Param("userId") + " not found",
-			"data":    nil,
-		})
+		c.Error(apperror.NotFound("user.not_found").With("id", c.Param("userId")))
 		return
 	}
 
+	//Photos are removed with the user, their stored objects are not
+	photos, _ := ctl.photos.ListByUser(user.ID)
+
 	//Delete user
-	err = db.Debug().Delete(&user).Error
+	err = ctl.users.Delete(user.ID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"status":  "Error",
-			"message": err.Error(),
-			"data":    nil,
-		})
+		c.Error(err)
+		return
+	}
+	for _, photo := range photos {
+		deletePhotoObject(c, ctl.store, photo)
+	}
+
+	//Response success
+	response.Success(c, http.StatusOK, "user.deleted", nil)
+}
+
+//Function to convert user into response data
+func toUserRegister(user models.User) app.UserRegister {
+	return app.UserRegister{
+		ID:               user.ID,
+		Username:         user.Username,
+		Email:            user.Email,
+		EmailVerified:    user.IsVerified(),
+		TwoFactorEnabled: user.HasTwoFactor(),
+		CreatedAt:        user.CreatedAt,
+		UpdatedAt:        user.UpdatedAt,
+	}
+}
+
+//Function to get one user, email and account state are only shown to the user and admin
+func (ctl *UserController) GetUser(c *gin.Context) {
+	//Get user data from AuthMiddleware
+	user_has_login := middlewares.CurrentUser(c)
+
+	//Check if user exist
+	user, err := ctl.users.FindByID(c.Param("userId"))
+	if err == repository.ErrNotFound {
+		c.Error(apperror.NotFound("user.not_found").With("id", c.Param("userId")))
+		return
+	} else if err != nil {
+		c.Error(err)
 		return
 	}
 
 	//Response success
-	c.JSON(http.StatusOK, gin.H{
-		"status":  "Success",
-		"message": "User deleted succesfully",
-		"data":    nil,
-	})
+	if !canManageUser(user_has_login, user.ID) {
+		response.Success(c, http.StatusOK, "data.retrieved", app.UserPublic{ID: user.ID, Username: user.Username, CreatedAt: user.CreatedAt})
+		return
+	}
+	response.Success(c, http.StatusOK, "data.retrieved", toUserRegister(user))
+}
+
+//Function to get user who has login
+func (ctl *UserController) GetMe(c *gin.Context) {
+	//Get user data from AuthMiddleware
+	user_has_login := middlewares.CurrentUser(c)
+
+	//Response success
+	response.Success(c, http.StatusOK, "data.retrieved", toUserRegister(user_has_login))
 }
@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"task-vix-btpns/app/apperror"
+	"task-vix-btpns/app/auth"
+	"task-vix-btpns/app/i18n"
+	"task-vix-btpns/app/response"
+	"task-vix-btpns/mailer"
+	"task-vix-btpns/middlewares"
+	"task-vix-btpns/models"
+	"task-vix-btpns/repository"
+)
+
+//Minimum time between two verification mails of one user
+const verifyResendInterval = time.Minute
+
+//Handlers of email verification endpoints
+type VerificationController struct {
+	users repository.UserRepository
+	mail  mailer.Mailer
+}
+
+//Function to create email verification handlers
+func NewVerificationController(repos repository.Repositories, mail mailer.Mailer) *VerificationController {
+	return &VerificationController{users: repos.Users, mail: mail}
+}
+
+//Function to get public url of this service, used in links sent by mail
+func appURL() string {
+	if public_url := os.Getenv("APP_URL"); public_url != "" {
+		return public_url
+	}
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+	return "http://localhost:" + port
+}
+
+//Function to mark verification link of user as sent now, refused when previous link was sent too recently.
+//Links are claimed before they are sent so concurrent requests can't send several mails.
+func claimVerificationSend(users repository.UserRepository, user models.User) error {
+	claimed, err := users.ClaimVerificationSend(user.ID, time.Now().Add(-verifyResendInterval))
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		wait := verifyResendInterval
+		if user.VerificationSentAt != nil {
+			wait = time.Until(user.VerificationSentAt.Add(verifyResendInterval))
+		}
+		if wait < time.Second {
+			wait = time.Second
+		}
+		return apperror.RateLimited("email.resend_limited", wait).With("seconds", strconv.Itoa(int(wait.Seconds()+0.5)))
+	}
+	return nil
+}
+
+//Function to mail verification link to user, mail is refused when previous one was sent too recently
+func sendVerificationMail(c *gin.Context, users repository.UserRepository, mail mailer.Mailer, user models.User) error {
+	err := claimVerificationSend(users, user)
+	if err != nil {
+		return err
+	}
+
+	//Link carries signed user id and email, nothing is stored
+	token, err := auth.GenerateVerifyToken(user.ID, user.Email)
+	if err != nil {
+		return err
+	}
+	lang := response.Language(c)
+	params := i18n.Params{
+		"username": user.Username,
+		"link":     appURL() + "/users/verify?token=" + url.QueryEscape(token),
+		"hours":    strconv.Itoa(int(auth.VerifyTokenTTL.Hours())),
+	}
+	return mail.Send(c.Request.Context(), mailer.Message{
+		To:      user.Email,
+		Subject: i18n.T(lang, "mail.verify_subject", params),
+		Body:    i18n.T(lang, "mail.verify_body", params),
+	})
+}
+
+//Function to confirm email with token from verification link
+func (ctl *VerificationController) VerifyEmail(c *gin.Context) {
+	//Check token of link
+	claims, err := auth.ParseVerifyToken(c.Query("token"))
+	if errors.Is(err, auth.ErrTokenExpired) {
+		c.Error(apperror.Field("token", "expired", "email.verify_expired"))
+		return
+	} else if err != nil {
+		c.Error(apperror.Field("token", "invalid", "email.verify_invalid"))
+		return
+	}
+
+	//Link of an email which user no longer has doesn't verify anything
+	verified, err := ctl.users.VerifyEmail(claims.Subject, claims.Email)
+	if err != nil {
+		c.Error(err)
+		return
+	} else if !verified {
+		c.Error(apperror.Field("token", "invalid", "email.verify_invalid"))
+		return
+	}
+
+	//Response success
+	response.Success(c, http.StatusOK, "email.verified", nil)
+}
+
+//Function to send verification link again to user who has login
+func (ctl *VerificationController) ResendVerification(c *gin.Context) {
+	//Get user data from AuthMiddleware
+	user_has_login := middlewares.CurrentUser(c)
+	if user_has_login.IsVerified() {
+		c.Error(apperror.Conflict("email.already_verified"))
+		return
+	}
+
+	//Send link, too frequent requests are rejected
+	err := sendVerificationMail(c, ctl.users, ctl.mail, user_has_login)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	//Response success
+	response.Success(c, http.StatusOK, "email.verification_sent", nil)
+}
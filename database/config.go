@@ -1,42 +1,76 @@
 package database
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
-	"task-vix-btpns/models"
+	"task-vix-btpns/database/migrations"
 
-	_ "github.com/go-sql-driver/mysql"
 	"github.com/jinzhu/gorm"
 	"github.com/joho/godotenv"
 )
 
-func ConnectDB() *gorm.DB {
+//Function to open database connection of DB_DRIVER without touching the schema
+//
+//Queries are logged with their values only when DB_DEBUG=true, values include password and token hashes
+func OpenDB() *gorm.DB {
 	godotenv.Load(".env")
 
-	DB_HOST := os.Getenv("DB_HOST")
-	DB_USER := os.Getenv("DB_USER")
 	DB_DRIVER := os.Getenv("DB_DRIVER")
-	DB_PASSWORD := os.Getenv("DB_PASSWORD")
-	DB_NAME := os.Getenv("DB_NAME")
-	DB_PORT := os.Getenv("DB_PORT")
+	if DB_DRIVER == "" {
+		DB_DRIVER = "mysql"
+	}
+	config := Config{
+		Host:     os.Getenv("DB_HOST"),
+		Port:     os.Getenv("DB_PORT"),
+		User:     os.Getenv("DB_USER"),
+		Password: os.Getenv("DB_PASSWORD"),
+		Name:     os.Getenv("DB_NAME"),
+		SSLMode:  os.Getenv("DB_SSLMODE"),
+	}
 
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local", DB_USER, DB_PASSWORD, DB_HOST, DB_PORT, DB_NAME)
-	db, err := gorm.Open("mysql", dsn) //Connecting to database
+	driver, ok := drivers[DB_DRIVER]
+	if !ok {
+		log.Fatalf("Database driver %s is not supported, use mysql, postgres or sqlite", DB_DRIVER)
+	}
 
+	db, err := gorm.Open(driver.dialect, driver.dsn(config)) //Connecting to database
 	if err != nil {
 		fmt.Printf("Cannot connect to %s database", DB_DRIVER)
 		log.Fatal(err)
 	}
+	if driver.setup != nil {
+		driver.setup(db, config)
+	}
+	if os.Getenv("DB_DEBUG") == "true" {
+		db.LogMode(true)
+	}
+
+	return db
+}
+
+//Function to create migrator for the connected database
+func NewMigrator(db *gorm.DB) (*migrations.Migrator, error) {
+	return migrations.New(db.DB(), db.Dialect().GetName())
+}
+
+//Function to connect to database and apply pending migrations, MIGRATE_ON_START=false only checks the schema is up to date
+func ConnectDB() *gorm.DB {
+	db := OpenDB()
 
-	err = db.Debug().AutoMigrate(&models.User{}, &models.Photo{}).Error //Migrate the tables to database
+	migrator, err := NewMigrator(db)
 	if err != nil {
-		log.Fatalf("Migrating table error: %v", err)
+		log.Fatalf("Loading migrations error: %v", err)
 	}
 
-	err = db.Debug().Model(&models.Photo{}).AddForeignKey("user_id", "users(id)", "cascade", "cascade").Error //Add foreign key to table
+	if os.Getenv("MIGRATE_ON_START") == "false" {
+		err = migrator.CheckLatest(context.Background())
+	} else {
+		err = migrator.Up(context.Background()) //Migrate the tables to database
+	}
 	if err != nil {
-		log.Fatalf("Error while attaching foreign key: %v", err)
+		log.Fatalf("Migrating table error: %v", err)
 	}
 
 	return db
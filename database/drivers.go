@@ -0,0 +1,184 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/postgres"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+)
+
+//Connection settings read from environment
+type Config struct {
+	Host     string
+	Port     string
+	User     string
+	Password string
+	Name     string //database name, file path for sqlite
+	SSLMode  string //postgres only
+}
+
+//Database driver selected by DB_DRIVER
+type driver struct {
+	dialect   string //gorm dialect name
+	dsn       func(c Config) string
+	setup     func(db *gorm.DB, c Config)
+	translate func(err error) error //convert error of this driver into typed error, other errors are returned as they are
+}
+
+var drivers = map[string]driver{
+	"mysql": {
+		dialect: "mysql",
+		dsn: func(c Config) string {
+			return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local", c.User, c.Password, c.Host, c.Port, c.Name)
+		},
+		translate: translateMySQL,
+	},
+	"postgres": {
+		dialect: "postgres",
+		dsn: func(c Config) string {
+			sslmode := c.SSLMode
+			if sslmode == "" {
+				sslmode = "disable"
+			}
+			return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s", c.Host, c.Port, c.User, c.Password, c.Name, sslmode)
+		},
+		translate: translatePostgres,
+	},
+	"sqlite": {
+		dialect: "sqlite3",
+		dsn: func(c Config) string {
+			if isMemory(c.Name) {
+				return "file::memory:?_foreign_keys=1"
+			}
+			return "file:" + c.Name + "?_foreign_keys=1&_busy_timeout=5000"
+		},
+		setup: func(db *gorm.DB, c Config) {
+			//In memory database lives as long as its connection, so only one connection is ever opened
+			if isMemory(c.Name) {
+				db.DB().SetMaxOpenConns(1)
+				db.DB().SetConnMaxLifetime(0)
+			}
+		},
+		translate: translateSQLite,
+	},
+}
+
+func isMemory(name string) bool {
+	return name == "" || name == ":memory:"
+}
+
+//Unique index or primary key already holds the value
+type UniqueViolationError struct {
+	Column     string //column of the index, best effort when driver only reports index name
+	Constraint string
+	Err        error
+}
+
+func (e *UniqueViolationError) Error() string { return e.Err.Error() }
+func (e *UniqueViolationError) Unwrap() error { return e.Err }
+
+//Referenced row doesn't exist, or row is still referenced
+type ForeignKeyViolationError struct {
+	Constraint string
+	Err        error
+}
+
+func (e *ForeignKeyViolationError) Error() string { return e.Err.Error() }
+func (e *ForeignKeyViolationError) Unwrap() error { return e.Err }
+
+//Function to translate unique and foreign key errors of any supported driver into typed error
+func TranslateError(err error) error {
+	if err == nil {
+		return nil
+	}
+	for _, d := range drivers {
+		if translated := d.translate(err); translated != err {
+			return translated
+		}
+	}
+	return err
+}
+
+//Function to get column from index name, indexes are named uix_<table>_<column>
+func indexColumn(table string, index string) string {
+	if index == "PRIMARY" || strings.HasSuffix(index, "_pkey") {
+		return "id"
+	}
+	column := strings.TrimPrefix(index, "uix_")
+	if table != "" {
+		column = strings.TrimPrefix(column, table+"_")
+	}
+	return column
+}
+
+//MySQL 8 prefixes the key with table name
+var mysqlDuplicateKey = regexp.MustCompile(`for key '(?:([^'.]+)\.)?([^']+)'`)
+
+func translateMySQL(err error) error {
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) {
+		return err
+	}
+	switch mysqlErr.Number {
+	case 1062: //ER_DUP_ENTRY
+		unique := &UniqueViolationError{Err: err}
+		if match := mysqlDuplicateKey.FindStringSubmatch(mysqlErr.Message); match != nil {
+			unique.Constraint = match[2]
+			unique.Column = indexColumn(match[1], match[2])
+		}
+		return unique
+	case 1451, 1452: //ER_ROW_IS_REFERENCED_2, ER_NO_REFERENCED_ROW_2
+		return &ForeignKeyViolationError{Err: err}
+	}
+	return err
+}
+
+//Detail of unique violation looks like: Key (email)=(user@mail.com) already exists.
+var postgresDuplicateKey = regexp.MustCompile(`Key \(([^)]+)\)=`)
+
+func translatePostgres(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+	switch pqErr.Code {
+	case "23505": //unique_violation
+		unique := &UniqueViolationError{Constraint: pqErr.Constraint, Err: err}
+		if match := postgresDuplicateKey.FindStringSubmatch(pqErr.Detail); match != nil {
+			unique.Column = match[1]
+		} else {
+			unique.Column = indexColumn(pqErr.Table, pqErr.Constraint)
+		}
+		return unique
+	case "23503": //foreign_key_violation
+		return &ForeignKeyViolationError{Constraint: pqErr.Constraint, Err: err}
+	}
+	return err
+}
+
+func translateSQLite(err error) error {
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) {
+		return err
+	}
+	switch sqliteErr.ExtendedCode {
+	case sqlite3.ErrConstraintUnique, sqlite3.ErrConstraintPrimaryKey:
+		//Message looks like: UNIQUE constraint failed: users.email
+		unique := &UniqueViolationError{Err: err}
+		if i := strings.LastIndex(sqliteErr.Error(), ": "); i >= 0 {
+			unique.Constraint = strings.Split(sqliteErr.Error()[i+2:], ", ")[0]
+			unique.Column = unique.Constraint[strings.Index(unique.Constraint, ".")+1:]
+		}
+		return unique
+	case sqlite3.ErrConstraintForeignKey:
+		return &ForeignKeyViolationError{Err: err}
+	}
+	return err
+}
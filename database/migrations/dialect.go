@@ -0,0 +1,124 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//LockTimeout is how long a replica waits, in seconds, for another replica to finish migrating
+const LockTimeout = 60
+
+//Database specific parts of migrator
+type dialect struct {
+	dir          string                                       //directory holding migration files
+	versionTable string                                       //statement creating schema_migrations table
+	lock         func(ctx context.Context, c *sql.Conn) error //take migration lock held by connection
+	unlock       func(ctx context.Context, c *sql.Conn) error
+	bind         func(query string) string //rewrite ? placeholders for driver
+}
+
+var dialects = map[string]dialect{
+	"mysql": {
+		dir: "mysql",
+		versionTable: `CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT NOT NULL PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			dirty BOOLEAN NOT NULL DEFAULT FALSE,
+			applied_at DATETIME NOT NULL
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`,
+		lock:   mysqlLock,
+		unlock: mysqlUnlock,
+		bind:   func(query string) string { return query },
+	},
+	"postgres": {
+		dir: "postgres",
+		versionTable: `CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT NOT NULL PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			dirty BOOLEAN NOT NULL DEFAULT FALSE,
+			applied_at TIMESTAMP WITH TIME ZONE NOT NULL
+		)`,
+		lock:   postgresLock,
+		unlock: postgresUnlock,
+		bind:   postgresBind,
+	},
+	"sqlite3": {
+		dir: "sqlite3",
+		versionTable: `CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT NOT NULL PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			dirty BOOLEAN NOT NULL DEFAULT FALSE,
+			applied_at DATETIME NOT NULL
+		)`,
+		//SQLite database file belongs to one process, there are no replicas to wait for
+		lock:   func(ctx context.Context, c *sql.Conn) error { return nil },
+		unlock: func(ctx context.Context, c *sql.Conn) error { return nil },
+		bind:   func(query string) string { return query },
+	},
+}
+
+//Function to take named MySQL lock, it is released when connection closes
+func mysqlLock(ctx context.Context, c *sql.Conn) error {
+	var locked sql.NullInt64
+	if err := c.QueryRowContext(ctx, "SELECT GET_LOCK('schema_migrations', ?)", LockTimeout).Scan(&locked); err != nil {
+		return err
+	}
+	if !locked.Valid || locked.Int64 != 1 {
+		return errors.New("Timeout while waiting for migration lock")
+	}
+	return nil
+}
+
+func mysqlUnlock(ctx context.Context, c *sql.Conn) error {
+	_, err := c.ExecContext(ctx, "SELECT RELEASE_LOCK('schema_migrations')")
+	return err
+}
+
+//Key of postgres advisory lock, any constant shared by every replica
+const postgresLockKey = 7483920115
+
+//Function to take postgres advisory lock, retried until LockTimeout since pg_advisory_lock would wait forever
+func postgresLock(ctx context.Context, c *sql.Conn) error {
+	deadline := time.Now().Add(LockTimeout * time.Second)
+	for {
+		var locked bool
+		if err := c.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", postgresLockKey).Scan(&locked); err != nil {
+			return err
+		}
+		if locked {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return errors.New("Timeout while waiting for migration lock")
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+func postgresUnlock(ctx context.Context, c *sql.Conn) error {
+	_, err := c.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", postgresLockKey)
+	return err
+}
+
+//Function to rewrite ? placeholders into $1, $2, ...
+func postgresBind(query string) string {
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,356 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//SQL files of every dialect, named <version>_<name>.up.sql and <version>_<name>.down.sql
+//
+//go:embed mysql/*.sql postgres/*.sql sqlite3/*.sql
+var files embed.FS
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+//State of one migration in database
+type Status struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	Dirty     bool //migration failed halfway, schema must be fixed by hand
+	AppliedAt *time.Time
+}
+
+//Migrator applies migrations of one dialect to database
+type Migrator struct {
+	db         *sql.DB
+	dialect    dialect
+	migrations []Migration
+}
+
+//Function to create migrator for dialect of database
+func New(db *sql.DB, dialectName string) (*Migrator, error) {
+	d, ok := dialects[dialectName]
+	if !ok {
+		return nil, fmt.Errorf("Migrations for %s are not available", dialectName)
+	}
+	migrations, err := load(d.dir)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, dialect: d, migrations: migrations}, nil
+}
+
+//Function to read migration files of dialect directory, sorted by version
+func load(dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(files, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("Migration file %s is not named <version>_<name>.(up|down).sql", entry.Name())
+		}
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		data, err := files.ReadFile(path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("Migration version %d is used by %s and %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(data)
+		} else {
+			migration.Down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("Migration %d_%s needs both up and down file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+//Function to get latest known migration version
+func (m *Migrator) Latest() int64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+//Function to list every migration and whether it has been applied
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var list []Status
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			status := Status{Version: migration.Version, Name: migration.Name}
+			if row, ok := applied[migration.Version]; ok {
+				status.Applied = !row.Dirty
+				status.Dirty = row.Dirty
+				status.AppliedAt = row.AppliedAt
+			}
+			list = append(list, status)
+		}
+		return nil
+	})
+	return list, err
+}
+
+//Function to apply every pending migration
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.To(ctx, m.Latest())
+}
+
+//Function to roll back the last steps applied migrations
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := checkDirty(applied); err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0 && steps > 0; i-- {
+			if _, ok := applied[m.migrations[i].Version]; !ok {
+				continue
+			}
+			if err := m.run(ctx, conn, m.migrations[i], false); err != nil {
+				return err
+			}
+			steps--
+		}
+		return nil
+	})
+}
+
+//Function to migrate up or down until version is the latest applied migration, 0 rolls back everything
+func (m *Migrator) To(ctx context.Context, version int64) error {
+	if version != 0 && m.find(version) == nil {
+		return fmt.Errorf("Migration version %d doesn't exist", version)
+	}
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := checkDirty(applied); err != nil {
+			return err
+		}
+
+		//Roll back newer migrations first, newest first
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; ok && migration.Version > version {
+				if err := m.run(ctx, conn, migration, false); err != nil {
+					return err
+				}
+			}
+		}
+		//Then apply missing migrations, oldest first
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; !ok && migration.Version <= version {
+				if err := m.run(ctx, conn, migration, true); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+//Function to mark version as cleanly applied after a dirty migration was fixed by hand
+func (m *Migrator) Force(ctx context.Context, version int64) error {
+	migration := m.find(version)
+	if migration == nil {
+		return fmt.Errorf("Migration version %d doesn't exist", version)
+	}
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		if _, err := conn.ExecContext(ctx, m.dialect.bind("DELETE FROM schema_migrations WHERE version = ?"), version); err != nil {
+			return err
+		}
+		_, err := conn.ExecContext(ctx, m.dialect.bind("INSERT INTO schema_migrations (version, name, dirty, applied_at) VALUES (?, ?, ?, ?)"),
+			version, migration.Name, false, time.Now())
+		return err
+	})
+}
+
+func (m *Migrator) find(version int64) *Migration {
+	for i := range m.migrations {
+		if m.migrations[i].Version == version {
+			return &m.migrations[i]
+		}
+	}
+	return nil
+}
+
+//Function to hold migration lock on one connection while fn runs, so replicas never migrate at once
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := m.dialect.lock(ctx, conn); err != nil {
+		return err
+	}
+	defer m.dialect.unlock(context.Background(), conn)
+
+	if _, err := conn.ExecContext(ctx, m.dialect.versionTable); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+//Row of schema_migrations table
+type appliedRow struct {
+	Dirty     bool
+	AppliedAt *time.Time
+}
+
+//Function to read applied migrations
+func (m *Migrator) applied(ctx context.Context, conn *sql.Conn) (map[int64]appliedRow, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, dirty, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int64]appliedRow{}
+	for rows.Next() {
+		var version int64
+		var row appliedRow
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &row.Dirty, &appliedAt); err != nil {
+			return nil, err
+		}
+		row.AppliedAt = &appliedAt
+		applied[version] = row
+	}
+	return applied, rows.Err()
+}
+
+//Function to refuse migrating while a previous migration is half applied
+func checkDirty(applied map[int64]appliedRow) error {
+	for version, row := range applied {
+		if row.Dirty {
+			return fmt.Errorf("Migration %d is dirty, fix the schema by hand then run `migrate force %d`", version, version)
+		}
+	}
+	return nil
+}
+
+//Function to apply or roll back one migration
+//
+//The migration is recorded as dirty before it runs, since MySQL commits DDL statements implicitly
+//and a failure can leave the schema half migrated. Postgres and SQLite roll back the whole migration.
+func (m *Migrator) run(ctx context.Context, conn *sql.Conn, migration Migration, up bool) error {
+	script := migration.Down
+	if up {
+		script = migration.Up
+		_, err := conn.ExecContext(ctx, m.dialect.bind("INSERT INTO schema_migrations (version, name, dirty, applied_at) VALUES (?, ?, ?, ?)"),
+			migration.Version, migration.Name, true, time.Now())
+		if err != nil {
+			return err
+		}
+	} else {
+		_, err := conn.ExecContext(ctx, m.dialect.bind("UPDATE schema_migrations SET dirty = ? WHERE version = ?"), true, migration.Version)
+		if err != nil {
+			return err
+		}
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	for _, statement := range splitStatements(script) {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("Migration %d_%s failed: %v", migration.Version, migration.Name, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	if up {
+		_, err = conn.ExecContext(ctx, m.dialect.bind("UPDATE schema_migrations SET dirty = ?, applied_at = ? WHERE version = ?"), false, time.Now(), migration.Version)
+	} else {
+		_, err = conn.ExecContext(ctx, m.dialect.bind("DELETE FROM schema_migrations WHERE version = ?"), migration.Version)
+	}
+	return err
+}
+
+//Function to split script into statements, a statement ends with ; at the end of a line
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	for _, line := range strings.Split(strings.ReplaceAll(script, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSuffix(strings.TrimSpace(current.String()), ";"))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
+
+//Function to check that migrations are applied up to the latest version
+func (m *Migrator) CheckLatest(ctx context.Context) error {
+	list, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+	for _, status := range list {
+		if !status.Applied {
+			return errors.New("Database schema is not up to date, run `migrate up`")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+-- Baseline schema. Before migrations existed AutoMigrate created users and photos with fewer
+-- columns, so both tables are created as AutoMigrate left them when they don't exist yet and
+-- every later column is added to them afterwards. Databases created by AutoMigrate get the
+-- same schema as new ones this way.
+
+CREATE TABLE IF NOT EXISTS users (
+    id VARCHAR(255) NOT NULL,
+    username VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    password VARCHAR(255) NOT NULL,
+    created_at DATETIME NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE KEY uix_users_email (email)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS photos (
+    id INT NOT NULL AUTO_INCREMENT,
+    title VARCHAR(255) NOT NULL,
+    caption VARCHAR(255) NOT NULL,
+    photo_url VARCHAR(255) NOT NULL,
+    user_id VARCHAR(255) NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT photos_user_id_users_id_foreign FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+ALTER TABLE users
+    ADD COLUMN role VARCHAR(50) NOT NULL DEFAULT 'user',
+    ADD COLUMN suspended_at DATETIME NULL;
+
+ALTER TABLE photos
+    ADD COLUMN storage_key VARCHAR(255) NULL,
+    ADD COLUMN metadata_removed VARCHAR(255) NULL,
+    ADD COLUMN variant_status VARCHAR(20) NULL,
+    ADD COLUMN is_profile BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN created_at DATETIME NULL DEFAULT CURRENT_TIMESTAMP,
+    ADD COLUMN updated_at DATETIME NULL DEFAULT CURRENT_TIMESTAMP,
+    ADD KEY idx_photos_user_id (user_id),
+    ADD KEY idx_photos_created_at (created_at, id);
+
+-- Photo of AutoMigrate databases was the only photo of its user, it stays the profile photo.
+UPDATE photos SET is_profile = TRUE;
+
+CREATE TABLE photo_variants (
+    id INT NOT NULL AUTO_INCREMENT,
+    photo_id INT NOT NULL,
+    size INT NOT NULL,
+    format VARCHAR(20) NOT NULL,
+    url VARCHAR(255) NOT NULL,
+    storage_key VARCHAR(255) NOT NULL,
+    PRIMARY KEY (id),
+    KEY idx_photo_variants_photo_id (photo_id),
+    CONSTRAINT photo_variants_photo_id_photos_id_foreign FOREIGN KEY (photo_id) REFERENCES photos (id) ON DELETE CASCADE ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE refresh_tokens (
+    id VARCHAR(255) NOT NULL,
+    user_id VARCHAR(255) NOT NULL,
+    family_id VARCHAR(255) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    replaced_by VARCHAR(255) NULL,
+    expires_at DATETIME NOT NULL,
+    revoked_at DATETIME NULL,
+    created_at DATETIME NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE KEY uix_refresh_tokens_token_hash (token_hash),
+    KEY idx_refresh_tokens_user_id (user_id),
+    KEY idx_refresh_tokens_family_id (family_id),
+    CONSTRAINT refresh_tokens_user_id_users_id_foreign FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE revoked_tokens (
+    jti VARCHAR(255) NOT NULL,
+    expires_at DATETIME NOT NULL,
+    created_at DATETIME NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (jti)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,6 @@
+ALTER TABLE users
+    ADD COLUMN email_verified_at DATETIME NULL,
+    ADD COLUMN verification_sent_at DATETIME NULL;
+
+-- Accounts created before verification existed stay usable.
+UPDATE users SET email_verified_at = CURRENT_TIMESTAMP;
@@ -0,0 +1 @@
+ALTER TABLE recovery_codes ADD UNIQUE KEY uix_recovery_codes_code_hash (code_hash);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS revoked_tokens;
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS photo_variants;
+DROP TABLE IF EXISTS photos;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,71 @@
+-- Baseline schema, same tables as the MySQL baseline. AutoMigrate only ever ran on MySQL,
+-- so there is no older schema to adopt and tables are created as they are.
+
+CREATE TABLE users (
+    id VARCHAR(255) NOT NULL,
+    username VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    password VARCHAR(255) NOT NULL,
+    role VARCHAR(50) NOT NULL DEFAULT 'user',
+    suspended_at TIMESTAMP WITH TIME ZONE NULL,
+    created_at TIMESTAMP WITH TIME ZONE NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT users_pkey PRIMARY KEY (id),
+    CONSTRAINT uix_users_email UNIQUE (email)
+);
+
+CREATE TABLE photos (
+    id SERIAL NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    caption VARCHAR(255) NOT NULL,
+    photo_url VARCHAR(255) NOT NULL,
+    storage_key VARCHAR(255) NULL,
+    metadata_removed VARCHAR(255) NULL,
+    variant_status VARCHAR(20) NULL,
+    user_id VARCHAR(255) NOT NULL,
+    is_profile BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT photos_pkey PRIMARY KEY (id),
+    CONSTRAINT photos_user_id_users_id_foreign FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE INDEX idx_photos_user_id ON photos (user_id);
+CREATE INDEX idx_photos_created_at ON photos (created_at, id);
+
+-- Photo of AutoMigrate databases was the only photo of its user, it stays the profile photo.
+UPDATE photos SET is_profile = TRUE;
+
+CREATE TABLE photo_variants (
+    id SERIAL NOT NULL,
+    photo_id INTEGER NOT NULL,
+    size INTEGER NOT NULL,
+    format VARCHAR(20) NOT NULL,
+    url VARCHAR(255) NOT NULL,
+    storage_key VARCHAR(255) NOT NULL,
+    CONSTRAINT photo_variants_pkey PRIMARY KEY (id),
+    CONSTRAINT photo_variants_photo_id_photos_id_foreign FOREIGN KEY (photo_id) REFERENCES photos (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE INDEX idx_photo_variants_photo_id ON photo_variants (photo_id);
+
+CREATE TABLE refresh_tokens (
+    id VARCHAR(255) NOT NULL,
+    user_id VARCHAR(255) NOT NULL,
+    family_id VARCHAR(255) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    replaced_by VARCHAR(255) NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE NULL,
+    created_at TIMESTAMP WITH TIME ZONE NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT refresh_tokens_pkey PRIMARY KEY (id),
+    CONSTRAINT uix_refresh_tokens_token_hash UNIQUE (token_hash),
+    CONSTRAINT refresh_tokens_user_id_users_id_foreign FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE INDEX idx_refresh_tokens_user_id ON refresh_tokens (user_id);
+CREATE INDEX idx_refresh_tokens_family_id ON refresh_tokens (family_id);
+
+CREATE TABLE revoked_tokens (
+    jti VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT revoked_tokens_pkey PRIMARY KEY (jti)
+);
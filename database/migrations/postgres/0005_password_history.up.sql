@@ -0,0 +1,9 @@
+CREATE TABLE password_histories (
+    id SERIAL NOT NULL,
+    user_id VARCHAR(255) NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT password_histories_pkey PRIMARY KEY (id),
+    CONSTRAINT password_histories_user_id_users_id_foreign FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE INDEX idx_password_histories_user_id ON password_histories (user_id);
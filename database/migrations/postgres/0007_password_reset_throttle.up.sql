@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN reset_sent_at TIMESTAMP WITH TIME ZONE NULL;
@@ -0,0 +1 @@
+ALTER TABLE recovery_codes ADD CONSTRAINT uix_recovery_codes_code_hash UNIQUE (code_hash);
@@ -0,0 +1,2 @@
+-- Codes are salted per user, so equal hashes of two users aren't a conflict.
+ALTER TABLE recovery_codes DROP CONSTRAINT uix_recovery_codes_code_hash;
@@ -0,0 +1,2 @@
+DROP INDEX idx_refresh_tokens_expires_at;
+DROP INDEX idx_revoked_tokens_expires_at;
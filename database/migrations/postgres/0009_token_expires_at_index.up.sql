@@ -0,0 +1,3 @@
+-- Expired rows are purged by expiry time.
+CREATE INDEX idx_refresh_tokens_expires_at ON refresh_tokens (expires_at);
+CREATE INDEX idx_revoked_tokens_expires_at ON revoked_tokens (expires_at);
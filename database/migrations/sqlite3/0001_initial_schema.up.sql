@@ -0,0 +1,62 @@
+-- Baseline schema, same tables as the MySQL and Postgres baselines.
+
+CREATE TABLE users (
+    id VARCHAR(255) NOT NULL PRIMARY KEY,
+    username VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    password VARCHAR(255) NOT NULL,
+    role VARCHAR(50) NOT NULL DEFAULT 'user',
+    suspended_at DATETIME NULL,
+    created_at DATETIME NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uix_users_email UNIQUE (email)
+);
+
+CREATE TABLE photos (
+    id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+    title VARCHAR(255) NOT NULL,
+    caption VARCHAR(255) NOT NULL,
+    photo_url VARCHAR(255) NOT NULL,
+    storage_key VARCHAR(255) NULL,
+    metadata_removed VARCHAR(255) NULL,
+    variant_status VARCHAR(20) NULL,
+    user_id VARCHAR(255) NOT NULL REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    is_profile BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at DATETIME NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_photos_user_id ON photos (user_id);
+CREATE INDEX idx_photos_created_at ON photos (created_at, id);
+
+-- Photo of AutoMigrate databases was the only photo of its user, it stays the profile photo.
+UPDATE photos SET is_profile = TRUE;
+
+CREATE TABLE photo_variants (
+    id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+    photo_id INTEGER NOT NULL REFERENCES photos (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    size INTEGER NOT NULL,
+    format VARCHAR(20) NOT NULL,
+    url VARCHAR(255) NOT NULL,
+    storage_key VARCHAR(255) NOT NULL
+);
+CREATE INDEX idx_photo_variants_photo_id ON photo_variants (photo_id);
+
+CREATE TABLE refresh_tokens (
+    id VARCHAR(255) NOT NULL PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    family_id VARCHAR(255) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    replaced_by VARCHAR(255) NULL,
+    expires_at DATETIME NOT NULL,
+    revoked_at DATETIME NULL,
+    created_at DATETIME NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uix_refresh_tokens_token_hash UNIQUE (token_hash)
+);
+CREATE INDEX idx_refresh_tokens_user_id ON refresh_tokens (user_id);
+CREATE INDEX idx_refresh_tokens_family_id ON refresh_tokens (family_id);
+
+CREATE TABLE revoked_tokens (
+    jti VARCHAR(255) NOT NULL PRIMARY KEY,
+    expires_at DATETIME NOT NULL,
+    created_at DATETIME NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,5 @@
+ALTER TABLE users ADD COLUMN email_verified_at DATETIME NULL;
+ALTER TABLE users ADD COLUMN verification_sent_at DATETIME NULL;
+
+-- Accounts created before verification existed stay usable.
+UPDATE users SET email_verified_at = CURRENT_TIMESTAMP;
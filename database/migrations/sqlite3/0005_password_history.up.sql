@@ -0,0 +1,7 @@
+CREATE TABLE password_histories (
+    id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+    user_id VARCHAR(255) NOT NULL REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    password_hash VARCHAR(255) NOT NULL,
+    created_at DATETIME NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_password_histories_user_id ON password_histories (user_id);
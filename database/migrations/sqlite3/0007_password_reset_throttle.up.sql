@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN reset_sent_at DATETIME NULL;
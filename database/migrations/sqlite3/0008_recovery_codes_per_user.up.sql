@@ -0,0 +1,14 @@
+-- Codes are salted per user, so equal hashes of two users aren't a conflict.
+-- SQLite can't drop a table constraint, the table is copied without it.
+CREATE TABLE recovery_codes_new (
+    id VARCHAR(255) NOT NULL PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at DATETIME NULL,
+    created_at DATETIME NULL DEFAULT CURRENT_TIMESTAMP
+);
+INSERT INTO recovery_codes_new (id, user_id, code_hash, used_at, created_at)
+    SELECT id, user_id, code_hash, used_at, created_at FROM recovery_codes;
+DROP TABLE recovery_codes;
+ALTER TABLE recovery_codes_new RENAME TO recovery_codes;
+CREATE INDEX idx_recovery_codes_user_id ON recovery_codes (user_id);
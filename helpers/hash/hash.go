@@ -0,0 +1,109 @@
+package hash
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"task-vix-btpns/helpers/config"
+)
+
+//ErrMismatch is returned when password doesn't match the hash
+var ErrMismatch = errors.New("Password doesn't match hash")
+
+//Hasher hashes passwords with one algorithm and its parameters, both are recorded in the encoded hash
+type Hasher interface {
+	Hash(password string) (string, error)
+	//Verify checks password against hash made by this algorithm with any parameters
+	Verify(encoded string, password string) error
+	//NeedsRehash tells if hash was made with other parameters than the hasher's
+	NeedsRehash(encoded string) bool
+}
+
+var (
+	hasher     Hasher
+	hasherErr  error
+	hasherOnce sync.Once
+)
+
+//Function to load hasher from environment, must be called after .env is loaded
+//
+//PASSWORD_HASH is bcrypt (default) or argon2id. BCRYPT_COST defaults to 14,
+//ARGON2_MEMORY (KiB), ARGON2_TIME and ARGON2_THREADS default to 19456, 2 and 1.
+func Load() error {
+	hasherOnce.Do(func() {
+		hasher, hasherErr = fromEnv()
+	})
+	return hasherErr
+}
+
+//Function to create hasher configured by environment
+func fromEnv() (Hasher, error) {
+	switch strings.ToLower(os.Getenv("PASSWORD_HASH")) {
+	case "", "bcrypt":
+		cost, err := config.Int("BCRYPT_COST", 14)
+		if err != nil {
+			return nil, err
+		}
+		return NewBcrypt(cost)
+	case "argon2id":
+		memory, err := config.Int("ARGON2_MEMORY", 19456)
+		if err != nil {
+			return nil, err
+		}
+		time, err := config.Int("ARGON2_TIME", 2)
+		if err != nil {
+			return nil, err
+		}
+		threads, err := config.Int("ARGON2_THREADS", 1)
+		if err != nil {
+			return nil, err
+		}
+		if memory < 0 || time < 0 || threads < 0 || threads > 255 {
+			return nil, errors.New("Argon2id parameters are out of range")
+		}
+		return NewArgon2id(uint32(memory), uint32(time), uint8(threads))
+	default:
+		return nil, fmt.Errorf("Password hash %s is not supported", os.Getenv("PASSWORD_HASH"))
+	}
+}
+
+//Function to get hasher of the algorithm which made the hash
+func hasherOf(encoded string) (Hasher, error) {
+	switch {
+	case strings.HasPrefix(encoded, "$argon2id$"):
+		return Argon2id{}, nil
+	case strings.HasPrefix(encoded, "$2a$"), strings.HasPrefix(encoded, "$2b$"), strings.HasPrefix(encoded, "$2y$"):
+		return Bcrypt{}, nil
+	default:
+		return nil, errors.New("Hash algorithm is not supported")
+	}
+}
+
+//function to be used to hash a password with the configured hasher
+func HashPassword(password string) ([]byte, error) {
+	if err := Load(); err != nil {
+		return nil, err
+	}
+	encoded, err := hasher.Hash(password)
+	return []byte(encoded), err
+}
+
+//function to be used to compare a password with a hash made by any supported algorithm
+func CheckPasswordHash(hash, password string) error {
+	h, err := hasherOf(hash)
+	if err != nil {
+		return err
+	}
+	return h.Verify(hash, password)
+}
+
+//Function to check if hash was made with other algorithm or parameters than the configured ones
+func NeedsRehash(hash string) bool {
+	if err := Load(); err != nil {
+		return false
+	}
+	return hasher.NeedsRehash(hash)
+}
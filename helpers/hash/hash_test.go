@@ -0,0 +1,56 @@
+package hash
+
+import (
+	"fmt"
+	"testing"
+)
+
+//Benchmark to pick cost of PASSWORD_HASH, run with -bench Hash and compare against login latency budget
+func BenchmarkHash(b *testing.B) {
+	hashers := []Hasher{}
+	for _, cost := range []int{10, 12, 14} {
+		hasher, err := NewBcrypt(cost)
+		if err != nil {
+			b.Fatal(err)
+		}
+		hashers = append(hashers, hasher)
+	}
+	for _, params := range []Argon2id{{Memory: 19456, Time: 2, Threads: 1}, {Memory: 47104, Time: 1, Threads: 1}, {Memory: 65536, Time: 3, Threads: 4}} {
+		hasher, err := NewArgon2id(params.Memory, params.Time, params.Threads)
+		if err != nil {
+			b.Fatal(err)
+		}
+		hashers = append(hashers, hasher)
+	}
+
+	//Hasher configured by environment, defaults when nothing is set
+	configured, err := fromEnv()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	run := func(name string, hasher Hasher) {
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := hasher.Hash("correct horse battery staple"); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+	for _, hasher := range hashers {
+		run(benchmarkName(hasher), hasher)
+	}
+	run("configured/"+benchmarkName(configured), configured)
+}
+
+//Function to name sub-benchmark after algorithm and its cost
+func benchmarkName(hasher Hasher) string {
+	switch h := hasher.(type) {
+	case Bcrypt:
+		return fmt.Sprintf("bcrypt/cost=%d", h.Cost)
+	case Argon2id:
+		return fmt.Sprintf("argon2id/m=%d,t=%d,p=%d", h.Memory, h.Time, h.Threads)
+	}
+	return fmt.Sprintf("%T", hasher)
+}
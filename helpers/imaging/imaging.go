@@ -0,0 +1,89 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"image/jpeg"
+	"io"
+	"strings"
+
+	_ "image/gif" //register decoders of accepted upload types
+	_ "image/png"
+
+	"github.com/chai2010/webp"
+	"golang.org/x/image/draw"
+)
+
+const (
+	FormatJPEG = "jpeg"
+	FormatWebP = "webp"
+)
+
+//Square avatar sizes generated for every uploaded photo
+var Sizes = []int{64, 128, 512}
+
+//Formats generated for every avatar size
+var Formats = []string{FormatJPEG, FormatWebP}
+
+//Content type and file extension of each format
+var formatTypes = map[string][2]string{
+	FormatJPEG: {"image/jpeg", ".jpg"},
+	FormatWebP: {"image/webp", ".webp"},
+}
+
+//Function to check if size is one of the generated avatar sizes
+func IsSize(size int) bool {
+	for _, s := range Sizes {
+		if s == size {
+			return true
+		}
+	}
+	return false
+}
+
+//Function to get content type of format
+func ContentType(format string) string {
+	return formatTypes[format][0]
+}
+
+//Function to get storage key of variant, derived from the key of the original photo
+func VariantKey(key string, size int, format string) string {
+	if dot := strings.LastIndex(key, "."); dot > strings.LastIndex(key, "/") {
+		key = key[:dot]
+	}
+	return fmt.Sprintf("%s_%d%s", key, size, formatTypes[format][1])
+}
+
+//Function to decode image of any accepted upload type
+func Decode(r io.Reader) (image.Image, error) {
+	img, _, err := image.Decode(r)
+	return img, err
+}
+
+//Function to crop center square of image and scale it to size x size
+func Square(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	side := bounds.Dx()
+	if bounds.Dy() < side {
+		side = bounds.Dy()
+	}
+	x := bounds.Min.X + (bounds.Dx()-side)/2
+	y := bounds.Min.Y + (bounds.Dy()-side)/2
+	crop := image.Rect(x, y, x+side, y+side)
+
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, crop, draw.Over, nil)
+	return dst
+}
+
+//Function to encode image in format
+func Encode(w io.Writer, img image.Image, format string) error {
+	switch format {
+	case FormatJPEG:
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: 85})
+	case FormatWebP:
+		return webp.Encode(w, img, &webp.Options{Quality: 80})
+	default:
+		return fmt.Errorf("Image format %s is not supported", format)
+	}
+}
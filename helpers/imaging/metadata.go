@@ -0,0 +1,290 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+)
+
+//Kinds of metadata found in uploaded image
+const (
+	MetaExif         = "exif"
+	MetaGPS          = "gps"
+	MetaCamera       = "camera"
+	MetaSerialNumber = "serial_number"
+	MetaDateTime     = "datetime"
+	MetaThumbnail    = "thumbnail"
+	MetaXMP          = "xmp"
+	MetaIPTC         = "iptc"
+	MetaICCProfile   = "icc_profile"
+	MetaComment      = "comment"
+	MetaApplication  = "application" //GIF application extension other than animation loop count
+)
+
+//Metadata found in image, everything in Found is dropped by re-encoding
+type Metadata struct {
+	Orientation int //EXIF orientation 1-8, 0 when missing
+	found       map[string]bool
+}
+
+//Function to list kinds of metadata found, sorted
+func (m Metadata) Found() []string {
+	list := make([]string, 0, len(m.found))
+	for kind := range m.found {
+		list = append(list, kind)
+	}
+	sort.Strings(list)
+	return list
+}
+
+func (m *Metadata) add(kind string) {
+	if m.found == nil {
+		m.found = map[string]bool{}
+	}
+	m.found[kind] = true
+}
+
+//Function to read metadata of JPEG, PNG, WebP or GIF image
+func ReadMetadata(data []byte, contentType string) Metadata {
+	meta := Metadata{}
+	switch contentType {
+	case "image/gif":
+		readGIFMetadata(data, &meta)
+	case "image/jpeg":
+		readJPEGMetadata(data, &meta)
+	case "image/png":
+		readPNGMetadata(data, &meta)
+	case "image/webp":
+		readWebPMetadata(data, &meta)
+	}
+	return meta
+}
+
+//Function to scan JPEG segments until image data starts
+func readJPEGMetadata(data []byte, meta *Metadata) {
+	pos := 2 //skip SOI marker
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return
+		}
+		marker := data[pos+1]
+		if marker == 0xDA || marker == 0xD9 { //start of scan or end of image
+			return
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2 : pos+4]))
+		if length < 2 || pos+2+length > len(data) {
+			return
+		}
+		segment := data[pos+4 : pos+2+length]
+
+		switch {
+		case marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")):
+			readTIFFMetadata(segment[6:], meta)
+		case marker == 0xE1 && bytes.HasPrefix(segment, []byte("http://ns.adobe.com/xap/1.0/")):
+			meta.add(MetaXMP)
+		case marker == 0xE2 && bytes.HasPrefix(segment, []byte("ICC_PROFILE")):
+			meta.add(MetaICCProfile)
+		case marker == 0xED:
+			meta.add(MetaIPTC)
+		case marker == 0xFE:
+			meta.add(MetaComment)
+		}
+		pos += 2 + length
+	}
+}
+
+//Function to scan PNG chunks
+func readPNGMetadata(data []byte, meta *Metadata) {
+	pos := 8 //skip signature
+	for pos+8 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[pos : pos+4]))
+		kind := string(data[pos+4 : pos+8])
+		if length < 0 || pos+12+length > len(data) {
+			return
+		}
+		chunk := data[pos+8 : pos+8+length]
+
+		switch kind {
+		case "eXIf":
+			readTIFFMetadata(chunk, meta)
+		case "iTXt":
+			if bytes.HasPrefix(chunk, []byte("XML:com.adobe.xmp")) {
+				meta.add(MetaXMP)
+			} else {
+				meta.add(MetaComment)
+			}
+		case "tEXt", "zTXt":
+			meta.add(MetaComment)
+		case "iCCP":
+			meta.add(MetaICCProfile)
+		case "tIME":
+			meta.add(MetaDateTime)
+		case "IEND":
+			return
+		}
+		pos += 12 + length
+	}
+}
+
+//Function to scan WebP RIFF chunks
+func readWebPMetadata(data []byte, meta *Metadata) {
+	pos := 12 //skip RIFF header
+	for pos+8 <= len(data) {
+		kind := string(data[pos : pos+4])
+		length := int(binary.LittleEndian.Uint32(data[pos+4 : pos+8]))
+		if length < 0 || pos+8+length > len(data) {
+			return
+		}
+		chunk := data[pos+8 : pos+8+length]
+
+		switch kind {
+		case "EXIF":
+			readTIFFMetadata(bytes.TrimPrefix(chunk, []byte("Exif\x00\x00")), meta)
+		case "XMP ":
+			meta.add(MetaXMP)
+		case "ICCP":
+			meta.add(MetaICCProfile)
+		}
+		pos += 8 + length + length%2 //chunks are padded to even size
+	}
+}
+
+//Function to skip GIF data sub-blocks starting at pos, returns position after the terminator or -1 when data ends first
+func skipGIFSubBlocks(data []byte, pos int) int {
+	for pos < len(data) {
+		size := int(data[pos])
+		pos++
+		if size == 0 {
+			return pos
+		}
+		pos += size
+	}
+	return -1
+}
+
+//Function to scan GIF blocks, extensions other than graphic control and animation loop are dropped by re-encoding
+func readGIFMetadata(data []byte, meta *Metadata) {
+	if len(data) < 13 {
+		return
+	}
+	pos := 13               //skip header and logical screen descriptor
+	if data[10]&0x80 != 0 { //global color table
+		pos += 3 << (data[10]&0x07 + 1)
+	}
+	for pos < len(data) {
+		switch data[pos] {
+		case 0x21: //extension
+			if pos+2 >= len(data) {
+				return
+			}
+			label := data[pos+1]
+			switch label {
+			case 0xFE, 0x01: //comment and plain text
+				meta.add(MetaComment)
+			case 0xFF:
+				size := int(data[pos+2])
+				if pos+3+size > len(data) {
+					return
+				}
+				switch string(data[pos+3 : pos+3+size]) {
+				case "NETSCAPE2.0", "ANIMEXTS1.0": //loop count, kept by re-encoding
+				case "XMP DataXMP":
+					meta.add(MetaXMP)
+				case "ICCRGBG1012":
+					meta.add(MetaICCProfile)
+				default:
+					meta.add(MetaApplication)
+				}
+			}
+			pos = skipGIFSubBlocks(data, pos+2)
+		case 0x2C: //image descriptor
+			if pos+10 > len(data) {
+				return
+			}
+			packed := data[pos+9]
+			pos += 10
+			if packed&0x80 != 0 { //local color table
+				pos += 3 << (packed&0x07 + 1)
+			}
+			pos = skipGIFSubBlocks(data, pos+1) //skip LZW minimum code size and image data
+		default: //trailer or broken data
+			return
+		}
+		if pos < 0 {
+			return
+		}
+	}
+}
+
+//EXIF tags which reveal something about the owner or the device
+var tiffTags = map[uint16]string{
+	0x010F: MetaCamera,       //Make
+	0x0110: MetaCamera,       //Model
+	0xA433: MetaCamera,       //LensMake
+	0xA434: MetaCamera,       //LensModel
+	0x0132: MetaDateTime,     //DateTime
+	0x9003: MetaDateTime,     //DateTimeOriginal
+	0x9004: MetaDateTime,     //DateTimeDigitized
+	0xA431: MetaSerialNumber, //BodySerialNumber
+	0xA435: MetaSerialNumber, //LensSerialNumber
+	0xC62F: MetaSerialNumber, //CameraSerialNumber
+	0x9286: MetaComment,      //UserComment
+	0x010E: MetaComment,      //ImageDescription
+}
+
+//Function to read TIFF structure used by EXIF
+func readTIFFMetadata(data []byte, meta *Metadata) {
+	meta.add(MetaExif)
+	if len(data) < 8 {
+		return
+	}
+
+	var order binary.ByteOrder
+	switch string(data[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return
+	}
+
+	//IFD0 may point to EXIF and GPS IFD, its next IFD is the thumbnail
+	ifd0 := int(order.Uint32(data[4:8]))
+	next := readIFD(data, ifd0, order, meta, 0)
+	if next > 0 {
+		meta.add(MetaThumbnail)
+	}
+}
+
+//Function to read one IFD, returns offset of next IFD
+func readIFD(data []byte, offset int, order binary.ByteOrder, meta *Metadata, depth int) int {
+	if depth > 2 || offset < 8 || offset+2 > len(data) {
+		return 0
+	}
+	count := int(order.Uint16(data[offset : offset+2]))
+	entries := offset + 2
+	if entries+count*12+4 > len(data) {
+		return 0
+	}
+
+	for i := 0; i < count; i++ {
+		entry := data[entries+i*12 : entries+i*12+12]
+		tag := order.Uint16(entry[0:2])
+		switch tag {
+		case 0x0112: //Orientation
+			if depth == 0 {
+				meta.Orientation = int(order.Uint16(entry[8:10]))
+			}
+		case 0x8769: //EXIF IFD
+			readIFD(data, int(order.Uint32(entry[8:12])), order, meta, depth+1)
+		case 0x8825: //GPS IFD
+			meta.add(MetaGPS)
+		default:
+			if kind, ok := tiffTags[tag]; ok {
+				meta.add(kind)
+			}
+		}
+	}
+	return int(order.Uint32(data[entries+count*12 : entries+count*12+4]))
+}
@@ -0,0 +1,97 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/gif"
+	"image/png"
+)
+
+const MaxPixels = 40000000 //largest image accepted, guards against decompression bombs
+
+//Function to re-encode image so that no metadata survives, returns kinds of metadata removed
+//
+//EXIF orientation is applied to the pixels first, so the image keeps looking the same without it.
+func Sanitize(data []byte, contentType string) ([]byte, []string, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, errors.New("Photo can't be decoded")
+	}
+	if config.Width*config.Height > MaxPixels {
+		return nil, nil, errors.New("Photo dimension is too large")
+	}
+
+	var buf bytes.Buffer
+	//GIF is re-encoded frame by frame so animation is kept, comments and extensions are dropped
+	if contentType == "image/gif" {
+		animation, err := gif.DecodeAll(bytes.NewReader(data))
+		if err != nil {
+			return nil, nil, errors.New("Photo can't be decoded")
+		}
+		animation.Config = image.Config{} //use size of first frame
+		if err := gif.EncodeAll(&buf, animation); err != nil {
+			return nil, nil, err
+		}
+		return buf.Bytes(), ReadMetadata(data, contentType).Found(), nil
+	}
+
+	meta := ReadMetadata(data, contentType)
+	img, err := Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, errors.New("Photo can't be decoded")
+	}
+	img = Orient(img, meta.Orientation)
+
+	switch contentType {
+	case "image/jpeg":
+		err = Encode(&buf, img, FormatJPEG)
+	case "image/webp":
+		err = Encode(&buf, img, FormatWebP)
+	default:
+		err = png.Encode(&buf, img)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return buf.Bytes(), meta.Found(), nil
+}
+
+//Function to apply EXIF orientation to pixels
+func Orient(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	dw, dh := w, h
+	if orientation >= 5 { //orientations 5-8 swap width and height
+		dw, dh = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: //flip horizontal
+				sx, sy = w-1-x, y
+			case 3: //rotate 180
+				sx, sy = w-1-x, h-1-y
+			case 4: //flip vertical
+				sx, sy = x, h-1-y
+			case 5: //transpose
+				sx, sy = y, x
+			case 6: //rotate 90 clockwise
+				sx, sy = y, h-1-x
+			case 7: //transverse
+				sx, sy = w-1-y, h-1-x
+			case 8: //rotate 90 counter clockwise
+				sx, sy = w-1-y, x
+			}
+			dst.Set(x, y, img.At(bounds.Min.X+sx, bounds.Min.Y+sy))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,213 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"task-vix-btpns/app/apperror"
+)
+
+const (
+	DefaultLimit = 20  //used when limit is not given
+	MaxLimit     = 100 //largest page size
+)
+
+const (
+	KindInt    = "int"
+	KindString = "string"
+	KindTime   = "time"
+)
+
+//Column which can be used for sorting and the type of its values
+type Field struct {
+	Column string
+	Kind   string
+}
+
+//Position after the last row of a page, encoded into next_cursor
+type Cursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    int    `json:"id"`
+}
+
+//Page requested by client
+type Params struct {
+	Limit  int
+	Sort   string //name of sort field
+	Desc   bool
+	Cursor *Cursor
+	field  Field
+}
+
+//Function to read limit, sort and cursor query, sort is one of fields and may be prefixed by - for descending order
+func Parse(limit string, sort string, cursor string, fields map[string]Field, defaultSort string) (Params, error) {
+	params := Params{Limit: DefaultLimit}
+
+	if limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > MaxLimit {
+			return params, apperror.Field("limit", "range", "pagination.limit").With("max", strconv.Itoa(MaxLimit))
+		}
+		params.Limit = n
+	}
+
+	if sort == "" {
+		sort = defaultSort
+	}
+	params.Desc = strings.HasPrefix(sort, "-")
+	params.Sort = strings.TrimPrefix(sort, "-")
+	field, ok := fields[params.Sort]
+	if !ok {
+		return params, apperror.Field("sort", "oneof", "pagination.sort").With("sort", params.Sort)
+	}
+	params.field = field
+
+	if cursor != "" {
+		decoded, err := decodeCursor(cursor)
+		if err != nil || decoded.Sort != sort {
+			return params, apperror.Field("cursor", "cursor", "pagination.cursor")
+		}
+		if _, err := params.value(decoded.Value); err != nil {
+			return params, apperror.Field("cursor", "cursor", "pagination.cursor")
+		}
+		params.Cursor = decoded
+	}
+	return params, nil
+}
+
+//Function to convert cursor value into the type of sort column
+func (p Params) value(raw string) (interface{}, error) {
+	switch p.field.Kind {
+	case KindInt:
+		return strconv.Atoi(raw)
+	case KindTime:
+		return time.Parse(time.RFC3339Nano, raw)
+	default:
+		return raw, nil
+	}
+}
+
+//Function to apply cursor, order and limit to query, one extra row is fetched to know if there is a next page
+func (p Params) Apply(db *gorm.DB, table string) *gorm.DB {
+	column := table + "." + p.field.Column
+	id := table + ".id"
+	op, direction := ">", "asc"
+	if p.Desc {
+		op, direction = "<", "desc"
+	}
+
+	if p.Cursor != nil {
+		value, _ := p.value(p.Cursor.Value)
+		if column == id {
+			db = db.Where(id+" "+op+" ?", p.Cursor.ID)
+		} else {
+			db = db.Where("("+column+" "+op+" ?) OR ("+column+" = ? AND "+id+" "+op+" ?)", value, value, p.Cursor.ID)
+		}
+	}
+	return db.Order(column + " " + direction).Order(id + " " + direction).Limit(p.Limit + 1)
+}
+
+//Function to check if row a comes before row b in page order, for stores which can't sort with SQL
+func (p Params) Before(aValue string, aID int, bValue string, bID int) bool {
+	cmp := 0
+	a, errA := p.value(aValue)
+	b, errB := p.value(bValue)
+	if errA == nil && errB == nil {
+		switch a := a.(type) {
+		case int:
+			cmp = compareInt(a, b.(int))
+		case time.Time:
+			if a.Before(b.(time.Time)) {
+				cmp = -1
+			} else if a.After(b.(time.Time)) {
+				cmp = 1
+			}
+		case string:
+			cmp = strings.Compare(a, b.(string))
+		}
+	}
+	if cmp == 0 {
+		cmp = compareInt(aID, bID)
+	}
+	if p.Desc {
+		return cmp > 0
+	}
+	return cmp < 0
+}
+
+//Function to check if row comes after cursor, every row does when there is no cursor
+func (p Params) AfterCursor(value string, id int) bool {
+	if p.Cursor == nil {
+		return true
+	}
+	return p.Before(p.Cursor.Value, p.Cursor.ID, value, id)
+}
+
+func compareInt(a int, b int) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
+
+//Function to encode cursor pointing after the row with sort value and id
+func (p Params) Next(value string, id int) string {
+	sort := p.Sort
+	if p.Desc {
+		sort = "-" + sort
+	}
+	data, _ := json.Marshal(Cursor{Sort: sort, Value: value, ID: id})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+//Function to decode cursor from query
+func decodeCursor(cursor string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+	decoded := &Cursor{}
+	if err := json.Unmarshal(data, decoded); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
+
+//Function to format time as cursor value
+func TimeValue(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+//Function to parse date filter, either RFC3339 time or YYYY-MM-DD date
+func ParseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", value, time.Local)
+}
+
+//Function to parse upper bound of date filter, YYYY-MM-DD date covers its whole day
+//
+//Date ends one microsecond before next day, the finest precision every database keeps
+func ParseEndTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return t, err
+	}
+	return t.AddDate(0, 0, 1).Add(-time.Microsecond), nil
+}
+
+//Function to escape LIKE wildcards of user input, query must use ESCAPE '!' which works in every dialect
+func EscapeLike(value string) string {
+	return strings.NewReplacer(`!`, `!!`, `%`, `!%`, `_`, `!_`).Replace(value)
+}
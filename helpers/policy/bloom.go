@@ -0,0 +1,129 @@
+package policy
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"math"
+	"os"
+	"strings"
+)
+
+//Bloom filter of SHA-1 digests, it may wrongly report a password as listed but never misses a listed one
+type Bloom struct {
+	bits   []uint64
+	size   uint64 //number of bits
+	hashes uint64 //bits set per entry
+}
+
+//Function to create filter sized for count entries with wanted false positive rate
+func NewBloom(count int, falsePositive float64) *Bloom {
+	if count < 1 {
+		count = 1
+	}
+	size := uint64(math.Ceil(-float64(count) * math.Log(falsePositive) / (math.Ln2 * math.Ln2)))
+	if size < 64 {
+		size = 64
+	}
+	hashes := uint64(math.Round(float64(size) / float64(count) * math.Ln2))
+	if hashes < 1 {
+		hashes = 1
+	}
+	return &Bloom{bits: make([]uint64, (size+63)/64), size: size, hashes: hashes}
+}
+
+//Function to get bit positions of digest, derived from two halves of digest by double hashing
+func (b *Bloom) positions(digest [sha1.Size]byte) []uint64 {
+	h1 := binary.BigEndian.Uint64(digest[0:8])
+	h2 := binary.BigEndian.Uint64(digest[8:16]) | 1
+	positions := make([]uint64, b.hashes)
+	for i := range positions {
+		positions[i] = (h1 + uint64(i)*h2) % b.size
+	}
+	return positions
+}
+
+func (b *Bloom) add(digest [sha1.Size]byte) {
+	for _, p := range b.positions(digest) {
+		b.bits[p/64] |= 1 << (p % 64)
+	}
+}
+
+func (b *Bloom) has(digest [sha1.Size]byte) bool {
+	for _, p := range b.positions(digest) {
+		if b.bits[p/64]&(1<<(p%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+//Function to add password to filter
+func (b *Bloom) Add(password string) {
+	b.add(sha1.Sum([]byte(password)))
+}
+
+//Function to check if password may be in filter
+func (b *Bloom) Has(password string) bool {
+	return b.has(sha1.Sum([]byte(password)))
+}
+
+//Function to read digest of list line, line is a plain password or SHA-1 hex digest
+//optionally followed by :count as in Have I Been Pwned downloads
+func lineDigest(line string) ([sha1.Size]byte, bool) {
+	var digest [sha1.Size]byte
+	if line == "" {
+		return digest, false
+	}
+	hexDigest := strings.SplitN(line, ":", 2)[0]
+	if len(hexDigest) == 2*sha1.Size {
+		if _, err := hex.Decode(digest[:], []byte(hexDigest)); err == nil {
+			return digest, true
+		}
+	}
+	return sha1.Sum([]byte(line)), true
+}
+
+//Function to build filter from file with one entry per line, file is read twice to size the filter
+func LoadBloom(path string, falsePositive float64) (*Bloom, error) {
+	if falsePositive <= 0 || falsePositive >= 1 {
+		return nil, errors.New("False positive rate must be between 0 and 1")
+	}
+	count := 0
+	err := readLines(path, func(line string) {
+		if _, ok := lineDigest(line); ok {
+			count++
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	bloom := NewBloom(count, falsePositive)
+	err = readLines(path, func(line string) {
+		if digest, ok := lineDigest(line); ok {
+			bloom.add(digest)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bloom, nil
+}
+
+//Function to call fn with every line of file, trailing spaces and carriage return are removed
+func readLines(path string, fn func(line string)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fn(strings.TrimRight(scanner.Text(), " \t\r"))
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,201 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"task-vix-btpns/app/apperror"
+	"task-vix-btpns/app/i18n"
+	"task-vix-btpns/helpers/config"
+	"task-vix-btpns/helpers/hash"
+)
+
+//Character classes a password can be required to contain
+const (
+	ClassLower  = "lower"
+	ClassUpper  = "upper"
+	ClassDigit  = "digit"
+	ClassSymbol = "symbol" //anything but letter or digit
+)
+
+//Username or email shorter than this isn't looked for in password, it would forbid too many passwords
+const minIdentityLength = 3
+
+//Rules which new passwords have to follow
+type Policy struct {
+	MinLength int      //characters
+	MaxLength int      //characters
+	Classes   []string //character classes password must contain
+	History   int      //last passwords which can't be used again, current one included
+	Breached  *Bloom   //passwords known from data breaches, nil when no list is loaded
+}
+
+//Account whose new password is checked
+type Account struct {
+	Username string
+	Email    string
+	Hashes   []string //hashes of current and previous passwords, newest first
+}
+
+var (
+	policy     *Policy
+	policyErr  error
+	policyOnce sync.Once
+)
+
+//Function to load policy from environment, must be called after .env is loaded
+//
+//PASSWORD_MIN_LENGTH and PASSWORD_MAX_LENGTH default to 8 and 128, PASSWORD_CLASSES lists
+//required classes out of lower, upper, digit and symbol separated by comma, none by default.
+//PASSWORD_HISTORY is number of last passwords which can't be reused, 5 by default and 0 to allow reuse.
+//PASSWORD_BREACHED_FILE is list of breached passwords, one plain password or SHA-1 hex per line,
+//it is kept in bloom filter with PASSWORD_BREACHED_FALSE_POSITIVE rate (0.001 by default).
+func Load() error {
+	policyOnce.Do(func() {
+		policy, policyErr = fromEnv()
+	})
+	return policyErr
+}
+
+//Function to create policy configured by environment
+func fromEnv() (*Policy, error) {
+	p := &Policy{}
+	var err error
+	if p.MinLength, err = config.Int("PASSWORD_MIN_LENGTH", 8); err != nil {
+		return nil, err
+	}
+	if p.MaxLength, err = config.Int("PASSWORD_MAX_LENGTH", 128); err != nil {
+		return nil, err
+	}
+	if p.History, err = config.Int("PASSWORD_HISTORY", 5); err != nil {
+		return nil, err
+	}
+	if p.MinLength < 1 || p.MaxLength < p.MinLength || p.History < 0 {
+		return nil, errors.New("Password length must be at least 1 with maximum not below minimum, history must not be negative")
+	}
+
+	for _, class := range strings.Split(os.Getenv("PASSWORD_CLASSES"), ",") {
+		class = strings.ToLower(strings.TrimSpace(class))
+		switch class {
+		case "":
+		case ClassLower, ClassUpper, ClassDigit, ClassSymbol:
+			p.Classes = append(p.Classes, class)
+		default:
+			return nil, fmt.Errorf("Password class %s is not supported", class)
+		}
+	}
+
+	if path := os.Getenv("PASSWORD_BREACHED_FILE"); path != "" {
+		rate := 0.001
+		if value := os.Getenv("PASSWORD_BREACHED_FALSE_POSITIVE"); value != "" {
+			if rate, err = strconv.ParseFloat(value, 64); err != nil {
+				return nil, errors.New("PASSWORD_BREACHED_FALSE_POSITIVE must be a number")
+			}
+		}
+		if p.Breached, err = LoadBloom(path, rate); err != nil {
+			return nil, fmt.Errorf("Loading breached passwords error: %w", err)
+		}
+	}
+	return p, nil
+}
+
+//Function to check if password contains a character of class
+func hasClass(password string, class string) bool {
+	for _, r := range password {
+		switch {
+		case class == ClassLower && unicode.IsLower(r),
+			class == ClassUpper && unicode.IsUpper(r),
+			class == ClassDigit && unicode.IsDigit(r),
+			class == ClassSymbol && !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			return true
+		}
+	}
+	return false
+}
+
+//Function to check if password contains identity, case is ignored
+func containsIdentity(password string, identity string) bool {
+	identity = strings.ToLower(strings.TrimSpace(identity))
+	return utf8.RuneCountInString(identity) >= minIdentityLength && strings.Contains(strings.ToLower(password), identity)
+}
+
+//Function to check if password matches one of hashes, hashes are verified concurrently since each one is slow
+func reused(password string, hashes []string) bool {
+	matches := make(chan bool, len(hashes))
+	for _, encoded := range hashes {
+		go func(encoded string) {
+			matches <- hash.CheckPasswordHash(encoded, password) == nil
+		}(encoded)
+	}
+	found := false
+	for range hashes {
+		found = <-matches || found
+	}
+	return found
+}
+
+//Function to get every rule password breaks, violations are reported on request field
+func (p *Policy) Violations(field string, password string, account Account) []apperror.FieldError {
+	violations := []apperror.FieldError{}
+	add := func(code string, message string, params i18n.Params) {
+		violations = append(violations, apperror.FieldError{Field: field, Code: code, Message: message, Params: params})
+	}
+
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		add("min", "validation.min", i18n.Params{"param": strconv.Itoa(p.MinLength)})
+	}
+	if length > p.MaxLength {
+		add("max", "validation.max", i18n.Params{"param": strconv.Itoa(p.MaxLength)})
+	}
+	for _, class := range p.Classes {
+		if !hasClass(password, class) {
+			add(class, "password."+class, nil)
+		}
+	}
+	if containsIdentity(password, account.Username) {
+		add("username", "password.username", nil)
+	}
+	if containsIdentity(password, account.Email) || containsIdentity(password, strings.SplitN(account.Email, "@", 2)[0]) {
+		add("email", "password.email", nil)
+	}
+	if p.Breached != nil && p.Breached.Has(password) {
+		add("breached", "password.breached", nil)
+	}
+	if p.History > 0 && len(account.Hashes) > 0 {
+		hashes := account.Hashes
+		if len(hashes) > p.History {
+			hashes = hashes[:p.History]
+		}
+		if reused(password, hashes) {
+			add("reused", "password.reused", i18n.Params{"count": strconv.Itoa(p.History)})
+		}
+	}
+	return violations
+}
+
+//Function to check password of request field against the loaded policy, error lists every broken rule
+func Check(field string, password string, account Account) error {
+	if err := Load(); err != nil {
+		return err
+	}
+	violations := policy.Violations(field, password, account)
+	if len(violations) > 0 {
+		return apperror.Validation(violations[0].Message, violations...)
+	}
+	return nil
+}
+
+//Function to get number of last passwords which can't be used again, current one included
+func History() int {
+	if err := Load(); err != nil {
+		return 0
+	}
+	return policy.History
+}
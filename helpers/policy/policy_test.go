@@ -0,0 +1,117 @@
+package policy
+
+import (
+	"errors"
+	"testing"
+
+	"task-vix-btpns/app/apperror"
+	"task-vix-btpns/helpers/hash"
+)
+
+//Function to get codes of violations in order they are reported
+func violationCodes(violations []apperror.FieldError) []string {
+	codes := []string{}
+	for _, violation := range violations {
+		codes = append(codes, violation.Code)
+	}
+	return codes
+}
+
+//Function to hash passwords with the cheapest bcrypt cost, newest password first
+func testHashes(t *testing.T, passwords ...string) []string {
+	hasher, err := hash.NewBcrypt(4)
+	if err != nil {
+		t.Fatalf("Creating hasher error: %v", err)
+	}
+	hashes := []string{}
+	for _, password := range passwords {
+		encoded, err := hasher.Hash(password)
+		if err != nil {
+			t.Fatalf("Hashing password error: %v", err)
+		}
+		hashes = append(hashes, encoded)
+	}
+	return hashes
+}
+
+func TestViolationsListEveryBrokenRule(t *testing.T) {
+	breached := NewBloom(10, 0.001)
+	breached.Add("password1")
+	policy := &Policy{MinLength: 8, MaxLength: 16, Classes: []string{ClassLower, ClassUpper, ClassDigit, ClassSymbol}, Breached: breached}
+	account := Account{Username: "bobby", Email: "robert@mail.com"}
+
+	tests := []struct {
+		password string
+		codes    []string
+	}{
+		{"Str0ng!pass", []string{}},
+		{"Ab1!", []string{"min"}},
+		{"Ab1!Ab1!Ab1!Ab1!Ab1!", []string{"max"}},
+		{"lowercase", []string{ClassUpper, ClassDigit, ClassSymbol}},
+		{"My!Bobby1pass", []string{"username"}},
+		{"Robert!Pass1", []string{"email"}},
+		{"password1", []string{ClassUpper, ClassSymbol, "breached"}},
+		{"bob", []string{"min", ClassUpper, ClassDigit, ClassSymbol}},
+	}
+	for _, test := range tests {
+		codes := violationCodes(policy.Violations("password", test.password, account))
+		if len(codes) != len(test.codes) {
+			t.Fatalf("Password %s breaks %v, want %v", test.password, codes, test.codes)
+		}
+		for i := range codes {
+			if codes[i] != test.codes[i] {
+				t.Fatalf("Password %s breaks %v, want %v", test.password, codes, test.codes)
+			}
+		}
+	}
+}
+
+func TestViolationsRejectPasswordsOfHistory(t *testing.T) {
+	policy := &Policy{MinLength: 8, MaxLength: 128, History: 2}
+	account := Account{Hashes: testHashes(t, "current-pass", "previous-pass", "oldest-pass")}
+
+	for _, password := range []string{"current-pass", "previous-pass"} {
+		codes := violationCodes(policy.Violations("new_password", password, account))
+		if len(codes) != 1 || codes[0] != "reused" {
+			t.Fatalf("Password %s of history breaks %v, want [reused]", password, codes)
+		}
+	}
+
+	//Password older than the history can be chosen again, so can a new one
+	for _, password := range []string{"oldest-pass", "brand-new-pass"} {
+		if codes := violationCodes(policy.Violations("new_password", password, account)); len(codes) != 0 {
+			t.Fatalf("Password %s breaks %v, want none", password, codes)
+		}
+	}
+
+	//History of zero allows reuse
+	policy.History = 0
+	if codes := violationCodes(policy.Violations("new_password", "current-pass", account)); len(codes) != 0 {
+		t.Fatalf("Password breaks %v without history, want none", codes)
+	}
+}
+
+func TestCheckReportsViolationsOnField(t *testing.T) {
+	for _, name := range []string{"PASSWORD_MIN_LENGTH", "PASSWORD_MAX_LENGTH", "PASSWORD_HISTORY", "PASSWORD_CLASSES", "PASSWORD_BREACHED_FILE"} {
+		t.Setenv(name, "")
+	}
+
+	if err := Check("new_password", "long-enough", Account{}); err != nil {
+		t.Fatalf("Password following default policy is refused: %v", err)
+	}
+
+	err := Check("new_password", "short", Account{Hashes: testHashes(t, "short")})
+	var appErr *apperror.Error
+	if !errors.As(err, &appErr) || appErr.Code != apperror.CodeValidation {
+		t.Fatalf("Checking short password returned %v, want validation error", err)
+	}
+	codes := violationCodes(appErr.Fields)
+	if len(codes) != 2 || codes[0] != "min" || codes[1] != "reused" {
+		t.Fatalf("Short reused password breaks %v, want [min reused]", codes)
+	}
+	for _, field := range appErr.Fields {
+		if field.Field != "new_password" {
+			t.Fatalf("Violation is reported on %s, want new_password", field.Field)
+		}
+	}
+}
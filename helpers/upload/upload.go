@@ -0,0 +1,94 @@
+package upload
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strconv"
+
+	"task-vix-btpns/app/apperror"
+	"task-vix-btpns/helpers/imaging"
+)
+
+const DefaultMaxSize = 5 << 20 //5 MiB, used when MAX_UPLOAD_SIZE is not set
+
+//Image types accepted for upload and their file extension
+var allowedTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+//Uploaded image, checked by its content
+type Image struct {
+	Data        []byte
+	ContentType string
+	Extension   string
+	Removed     []string //kinds of metadata removed by Sanitize
+}
+
+//Function to get max upload size in bytes from MAX_UPLOAD_SIZE
+func MaxSize() int64 {
+	size, err := strconv.ParseInt(os.Getenv("MAX_UPLOAD_SIZE"), 10, 64)
+	if err != nil || size <= 0 {
+		return DefaultMaxSize
+	}
+	return size
+}
+
+//Function to read uploaded image, content type is detected from the file bytes
+func ReadImage(file *multipart.FileHeader, maxSize int64) (*Image, error) {
+	if file.Size > maxSize {
+		return nil, apperror.Field("photo", "max", "photo.too_large").With("max", strconv.FormatInt(maxSize, 10))
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	//Read one more byte than allowed to detect oversized file when size header lies
+	data, err := io.ReadAll(io.LimitReader(src, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, apperror.Field("photo", "max", "photo.too_large").With("max", strconv.FormatInt(maxSize, 10))
+	}
+	if len(data) == 0 {
+		return nil, apperror.Field("photo", "required", "photo.empty")
+	}
+
+	contentType := http.DetectContentType(data)
+	extension, ok := allowedTypes[contentType]
+	if !ok {
+		return nil, apperror.Field("photo", "type", "photo.type").With("type", contentType)
+	}
+
+	return &Image{Data: data, ContentType: contentType, Extension: extension}, nil
+}
+
+//Function to get reader of image data
+func (i *Image) Reader() io.Reader {
+	return bytes.NewReader(i.Data)
+}
+
+//Function to get size of image data
+func (i *Image) Size() int64 {
+	return int64(len(i.Data))
+}
+
+//Function to re-encode image without any metadata, orientation is applied to pixels
+func (i *Image) Sanitize() error {
+	data, removed, err := imaging.Sanitize(i.Data, i.ContentType)
+	if err != nil {
+		return err
+	}
+	i.Data = data
+	i.Removed = removed
+	return nil
+}
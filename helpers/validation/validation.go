@@ -0,0 +1,88 @@
+package validation
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+	"task-vix-btpns/app/apperror"
+	"task-vix-btpns/app/i18n"
+)
+
+//Rules of an operation are read from the struct tag named after it, e.g. `register:"required,email"`
+var (
+	mu         sync.Mutex
+	validators = map[string]*validator.Validate{}
+)
+
+//Function to get validator reading rules of operation, validators are created once per operation
+func forOperation(operation string) *validator.Validate {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if v, ok := validators[operation]; ok {
+		return v
+	}
+	v := validator.New()
+	v.SetTagName(operation)
+	v.RegisterTagNameFunc(jsonName) //Report fields by their JSON name
+	v.RegisterValidation("scheme", validScheme)
+	validators[operation] = v
+	return v
+}
+
+//Function to get JSON name of struct field
+func jsonName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
+
+//Function to check that value is an absolute URL with one of the schemes given as param, e.g. scheme=http https
+func validScheme(fl validator.FieldLevel) bool {
+	u, err := url.Parse(fl.Field().String())
+	if err != nil || u.Host == "" {
+		return false
+	}
+	for _, scheme := range strings.Fields(fl.Param()) {
+		if strings.EqualFold(u.Scheme, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+//Function to validate struct with rules of operation, every failing field is reported
+func Struct(operation string, s interface{}) error {
+	err := forOperation(strings.ToLower(operation)).Struct(s)
+	var failed validator.ValidationErrors
+	if !errors.As(err, &failed) {
+		return err
+	}
+
+	fields := make([]apperror.FieldError, 0, len(failed))
+	for _, fe := range failed {
+		fields = append(fields, apperror.FieldError{
+			Field:   fe.Field(),
+			Code:    fe.Tag(),
+			Message: message(fe.Tag()),
+			Params:  i18n.Params{"param": strings.Join(strings.Fields(fe.Param()), "/")},
+		})
+	}
+	return apperror.Validation(fields[0].Message, fields...)
+}
+
+//Function to get catalog key of failed rule, {field} and {param} are filled when the error is localized
+func message(tag string) string {
+	switch tag {
+	case "required", "min", "max", "scheme":
+		return "validation." + tag
+	default:
+		return "validation.invalid"
+	}
+}
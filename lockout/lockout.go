@@ -0,0 +1,173 @@
+package lockout
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"task-vix-btpns/helpers/config"
+)
+
+//Store keeps failure counters and locks, Redis store is shared by every replica
+type Store interface {
+	//Fail counts failed attempt of key and returns failures since the counter was created,
+	//counter is forgotten once ttl passes without new failure
+	Fail(ctx context.Context, key string, ttl time.Duration) (int, error)
+	//Lock blocks key for duration
+	Lock(ctx context.Context, key string, duration time.Duration) error
+	//LockedFor returns time left until key is unlocked, zero when it isn't locked
+	LockedFor(ctx context.Context, key string) (time.Duration, error)
+	//Reset forgets failures and lock of key
+	Reset(ctx context.Context, key string) error
+}
+
+//Function to create store based on LOCKOUT_DRIVER, memory is used by default
+func New() (Store, error) {
+	switch strings.ToLower(os.Getenv("LOCKOUT_DRIVER")) {
+	case "", "memory":
+		return NewMemory(), nil
+	case "redis":
+		return NewRedis(config.Get("REDIS_URL", "redis://localhost:6379/0"), config.Get("REDIS_PREFIX", "task-vix-btpns:"))
+	default:
+		return nil, fmt.Errorf("Lockout driver %s is not supported", os.Getenv("LOCKOUT_DRIVER"))
+	}
+}
+
+//Policy tells when a key is locked and for how long
+type Policy struct {
+	MaxFailures int           //failures allowed before key is locked
+	BaseLock    time.Duration //first lock, doubled by every further failure
+	MaxLock     time.Duration
+	Window      time.Duration //failures are forgotten after this long without new failure
+}
+
+//Function to get lock earned by number of failures, zero while failures are below the limit
+func (p Policy) lockFor(failures int) time.Duration {
+	if failures < p.MaxFailures {
+		return 0
+	}
+	lock := p.BaseLock
+	for i := p.MaxFailures; i < failures && lock < p.MaxLock; i++ {
+		lock *= 2
+	}
+	if lock > p.MaxLock {
+		lock = p.MaxLock
+	}
+	return lock
+}
+
+//Guard tracks failed logins per account and per IP address, and password reset requests per IP address
+type Guard struct {
+	store   Store
+	account Policy
+	ip      Policy
+	reset   Policy
+}
+
+//Function to create guard with policies from environment
+//
+//LOGIN_MAX_FAILURES and LOGIN_IP_MAX_FAILURES are failures allowed per account and per IP,
+//LOGIN_LOCK_BASE, LOGIN_LOCK_MAX and LOGIN_FAILURE_WINDOW are durations like 1m or 1h.
+//RESET_IP_MAX_REQUESTS is password reset requests allowed per IP before the same locks apply.
+func NewGuard(store Store) *Guard {
+	base := config.PositiveDuration("LOGIN_LOCK_BASE", time.Minute)
+	max := config.PositiveDuration("LOGIN_LOCK_MAX", time.Hour)
+	window := config.PositiveDuration("LOGIN_FAILURE_WINDOW", time.Hour)
+	return &Guard{
+		store:   store,
+		account: Policy{MaxFailures: config.PositiveInt("LOGIN_MAX_FAILURES", 5), BaseLock: base, MaxLock: max, Window: window},
+		ip:      Policy{MaxFailures: config.PositiveInt("LOGIN_IP_MAX_FAILURES", 20), BaseLock: base, MaxLock: max, Window: window},
+		reset:   Policy{MaxFailures: config.PositiveInt("RESET_IP_MAX_REQUESTS", 10), BaseLock: base, MaxLock: max, Window: window},
+	}
+}
+
+//Function to get store keys of account and IP address
+func accountKey(account string) string {
+	return "login:account:" + strings.ToLower(strings.TrimSpace(account))
+}
+
+func ipKey(ip string) string {
+	return "login:ip:" + ip
+}
+
+func resetKey(ip string) string {
+	return "reset:ip:" + ip
+}
+
+//Function to get time left until login of account from ip is allowed, zero when it is allowed now
+func (g *Guard) Check(ctx context.Context, account string, ip string) (time.Duration, error) {
+	account_lock, err := g.store.LockedFor(ctx, accountKey(account))
+	if err != nil {
+		return 0, err
+	}
+	ip_lock, err := g.store.LockedFor(ctx, ipKey(ip))
+	if err != nil {
+		return 0, err
+	}
+	if ip_lock > account_lock {
+		return ip_lock, nil
+	}
+	return account_lock, nil
+}
+
+//Function to record failed login, returns lock started by this failure
+func (g *Guard) Fail(ctx context.Context, account string, ip string) (time.Duration, error) {
+	var locked time.Duration
+	for _, target := range []struct {
+		key    string
+		policy Policy
+	}{
+		{accountKey(account), g.account},
+		{ipKey(ip), g.ip},
+	} {
+		failures, err := g.store.Fail(ctx, target.key, target.policy.Window)
+		if err != nil {
+			return 0, err
+		}
+		lock := target.policy.lockFor(failures)
+		if lock == 0 {
+			continue
+		}
+		if err := g.store.Lock(ctx, target.key, lock); err != nil {
+			return 0, err
+		}
+		if lock > locked {
+			locked = lock
+		}
+	}
+	if locked > 0 {
+		log.Printf("Login of %s from %s locked for %s", account, ip, locked)
+	}
+	return locked, nil
+}
+
+//Function to forget failures of account after successful login, failures of IP address are kept
+func (g *Guard) Succeed(ctx context.Context, account string) error {
+	return g.store.Reset(ctx, accountKey(account))
+}
+
+//Function to count password reset request from ip, returns time left until ip may request again
+//when it is locked, every request counts whether it names a registered email or not
+func (g *Guard) ResetRequest(ctx context.Context, ip string) (time.Duration, error) {
+	key := resetKey(ip)
+	locked, err := g.store.LockedFor(ctx, key)
+	if err != nil || locked > 0 {
+		return locked, err
+	}
+	requests, err := g.store.Fail(ctx, key, g.reset.Window)
+	if err != nil {
+		return 0, err
+	}
+	if lock := g.reset.lockFor(requests); lock > 0 {
+		return 0, g.store.Lock(ctx, key, lock)
+	}
+	return 0, nil
+}
+
+//Function to unlock account, used by admin
+func (g *Guard) Unlock(ctx context.Context, account string) error {
+	return g.store.Reset(ctx, accountKey(account))
+}
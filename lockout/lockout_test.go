@@ -0,0 +1,106 @@
+package lockout
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+var testPolicy = Policy{MaxFailures: 3, BaseLock: time.Minute, MaxLock: 10 * time.Minute, Window: time.Hour}
+
+func TestLockForDoublesUntilMax(t *testing.T) {
+	expected := map[int]time.Duration{
+		0:   0,
+		1:   0,
+		2:   0,
+		3:   time.Minute,
+		4:   2 * time.Minute,
+		5:   4 * time.Minute,
+		6:   8 * time.Minute,
+		7:   10 * time.Minute,
+		8:   10 * time.Minute,
+		100: 10 * time.Minute,
+	}
+	for failures, lock := range expected {
+		if got := testPolicy.lockFor(failures); got != lock {
+			t.Fatalf("Lock after %d failures is %s, want %s", failures, got, lock)
+		}
+	}
+}
+
+func TestLockForBaseAboveMax(t *testing.T) {
+	policy := Policy{MaxFailures: 1, BaseLock: time.Hour, MaxLock: time.Minute}
+	if got := policy.lockFor(1); got != time.Minute {
+		t.Fatalf("Lock is %s, want it capped at %s", got, time.Minute)
+	}
+}
+
+//Function to create guard on memory store where account and IP address share testPolicy
+func testGuard() *Guard {
+	return &Guard{store: NewMemory(), account: testPolicy, ip: testPolicy, reset: testPolicy}
+}
+
+func TestGuardLocksAccountAfterMaxFailures(t *testing.T) {
+	ctx := context.Background()
+	guard := testGuard()
+	for i := 1; i <= testPolicy.MaxFailures; i++ {
+		if wait, _ := guard.Check(ctx, "bob@mail.com", "10.0.0.1"); wait != 0 {
+			t.Fatalf("Login is locked before failure %d", i)
+		}
+		locked, err := guard.Fail(ctx, "Bob@Mail.com ", "10.0.0.1")
+		if err != nil {
+			t.Fatalf("Recording failure error: %v", err)
+		}
+		if (i == testPolicy.MaxFailures) != (locked == time.Minute) {
+			t.Fatalf("Failure %d locked login for %s", i, locked)
+		}
+	}
+	if wait, _ := guard.Check(ctx, "bob@mail.com", "10.0.0.2"); wait <= 0 || wait > time.Minute {
+		t.Fatalf("Account is locked for %s from another address, want up to %s", wait, time.Minute)
+	}
+
+	//Unlocked account is locked again by the next failures only
+	if err := guard.Unlock(ctx, "bob@mail.com"); err != nil {
+		t.Fatalf("Unlocking account error: %v", err)
+	}
+	if wait, _ := guard.Check(ctx, "bob@mail.com", "10.0.0.2"); wait != 0 {
+		t.Fatalf("Unlocked account is locked for %s", wait)
+	}
+}
+
+func TestGuardSucceedKeepsFailuresOfAddress(t *testing.T) {
+	ctx := context.Background()
+	guard := testGuard()
+	for i := 0; i < testPolicy.MaxFailures-1; i++ {
+		guard.Fail(ctx, "bob@mail.com", "10.0.0.1")
+	}
+	guard.Succeed(ctx, "bob@mail.com")
+
+	//Account starts counting again, address reaches its limit
+	locked, _ := guard.Fail(ctx, "carol@mail.com", "10.0.0.1")
+	if locked != time.Minute {
+		t.Fatalf("Address isn't locked after %d failures", testPolicy.MaxFailures)
+	}
+	if wait, _ := guard.Check(ctx, "bob@mail.com", "10.0.0.3"); wait != 0 {
+		t.Fatalf("Account is locked for %s after successful login", wait)
+	}
+}
+
+func TestGuardLimitsResetRequestsOfAddress(t *testing.T) {
+	ctx := context.Background()
+	guard := testGuard()
+	for i := 1; i <= testPolicy.MaxFailures; i++ {
+		if wait, err := guard.ResetRequest(ctx, "10.0.0.1"); err != nil || wait != 0 {
+			t.Fatalf("Reset request %d waits %s, error %v", i, wait, err)
+		}
+	}
+	if wait, _ := guard.ResetRequest(ctx, "10.0.0.1"); wait <= 0 {
+		t.Fatalf("Reset request over the limit isn't refused")
+	}
+	if wait, _ := guard.ResetRequest(ctx, "10.0.0.2"); wait != 0 {
+		t.Fatalf("Reset request of another address waits %s", wait)
+	}
+	if wait, _ := guard.Check(ctx, "bob@mail.com", "10.0.0.1"); wait != 0 {
+		t.Fatalf("Reset requests locked login for %s", wait)
+	}
+}
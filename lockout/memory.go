@@ -0,0 +1,95 @@
+package lockout
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+//Entries are swept when store grows past this size, so addresses that never come back don't pile up
+const memorySweepSize = 10000
+
+type memoryEntry struct {
+	failures    int
+	expiresAt   time.Time //counter is forgotten after this time
+	lockedUntil time.Time
+}
+
+//Store keeping counters in memory of one process, used when there is a single replica
+type Memory struct {
+	mu      sync.Mutex
+	entries map[string]*memoryEntry
+}
+
+//Function to create in-memory store
+func NewMemory() *Memory {
+	return &Memory{entries: map[string]*memoryEntry{}}
+}
+
+//Function to get live entry of key, expired entry is removed
+func (m *Memory) entry(key string, now time.Time) *memoryEntry {
+	entry, ok := m.entries[key]
+	if ok && now.After(entry.expiresAt) && now.After(entry.lockedUntil) {
+		delete(m.entries, key)
+		return nil
+	}
+	return entry
+}
+
+//Function to remove every expired entry
+func (m *Memory) sweep(now time.Time) {
+	for key := range m.entries {
+		m.entry(key, now)
+	}
+}
+
+func (m *Memory) Fail(ctx context.Context, key string, ttl time.Duration) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	if len(m.entries) >= memorySweepSize {
+		m.sweep(now)
+	}
+	entry := m.entry(key, now)
+	if entry == nil {
+		entry = &memoryEntry{}
+		m.entries[key] = entry
+	}
+	if now.After(entry.expiresAt) {
+		entry.failures = 0
+	}
+	entry.failures++
+	entry.expiresAt = now.Add(ttl)
+	return entry.failures, nil
+}
+
+func (m *Memory) Lock(ctx context.Context, key string, duration time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	entry := m.entry(key, now)
+	if entry == nil {
+		entry = &memoryEntry{}
+		m.entries[key] = entry
+	}
+	entry.lockedUntil = now.Add(duration)
+	return nil
+}
+
+func (m *Memory) LockedFor(ctx context.Context, key string) (time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	entry := m.entry(key, now)
+	if entry == nil || !entry.lockedUntil.After(now) {
+		return 0, nil
+	}
+	return entry.lockedUntil.Sub(now), nil
+}
+
+func (m *Memory) Reset(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.entries, key)
+	return nil
+}
@@ -0,0 +1,60 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"task-vix-btpns/helpers/config"
+)
+
+//Plain text mail sent to one recipient
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+//Mailer delivers mail to users
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+//Function to create mailer based on MAIL_DRIVER, smtp is used by default when SMTP_HOST is set
+//
+//Log mailer writes reset links to log, so it has to be asked for with MAIL_DRIVER=log
+func New() (Mailer, error) {
+	driver := strings.ToLower(os.Getenv("MAIL_DRIVER"))
+	if driver == "" && os.Getenv("SMTP_HOST") != "" {
+		driver = "smtp"
+	}
+
+	switch driver {
+	case "smtp":
+		return NewSMTP(SMTPConfig{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     config.Get("SMTP_PORT", "587"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     config.Get("MAIL_FROM", "no-reply@localhost"),
+			StartTLS: os.Getenv("SMTP_STARTTLS") != "false",
+		})
+	case "":
+		return nil, fmt.Errorf("MAIL_DRIVER or SMTP_HOST must be set, use MAIL_DRIVER=log to write mail to log in development")
+	case "log":
+		log.Println("MAIL_DRIVER is log, mail is written to log instead of being sent")
+		return Log{}, nil
+	default:
+		return nil, fmt.Errorf("Mail driver %s is not supported", os.Getenv("MAIL_DRIVER"))
+	}
+}
+
+//Mailer which only writes mail to log, used in development
+type Log struct{}
+
+func (Log) Send(ctx context.Context, msg Message) error {
+	log.Printf("Mail to %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
@@ -1,16 +1,67 @@
 package main
 
 import (
+	"log"
 	"os"
+	"task-vix-btpns/app/auth"
 	"task-vix-btpns/database"
-	"task-vix-btpns/models"
+	"task-vix-btpns/helpers/config"
+	"task-vix-btpns/helpers/hash"
+	"task-vix-btpns/helpers/policy"
+	"task-vix-btpns/lockout"
+	"task-vix-btpns/mailer"
+	"task-vix-btpns/repository"
 	"task-vix-btpns/router"
+	"task-vix-btpns/storage"
+	"task-vix-btpns/worker"
+	"time"
+
+	"github.com/joho/godotenv"
 )
 
 func main() {
+	godotenv.Load(".env") //Load .env before anything reads the environment
+
+	//Migrations only need the database, keys and password settings aren't loaded for them
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(os.Args[2:]))
+	}
+
+	if err := auth.LoadKeys(); err != nil {
+		log.Fatalf("Loading JWT keys error: %v", err)
+	}
+	if err := hash.Load(); err != nil {
+		log.Fatalf("Loading password hasher error: %v", err)
+	}
+	if err := policy.Load(); err != nil {
+		log.Fatalf("Loading password policy error: %v", err)
+	}
+
 	db := database.ConnectDB()
-	db.AutoMigrate(&models.User{})
+	repos := repository.NewGormRepositories(db)
+
+	store, err := storage.New() //Storage for uploaded photos
+	if err != nil {
+		log.Fatalf("Initializing storage error: %v", err)
+	}
+
+	mail, err := mailer.New() //Mailer for password reset links
+	if err != nil {
+		log.Fatalf("Initializing mailer error: %v", err)
+	}
+
+	lockouts, err := lockout.New() //Counters of failed logins, shared by replicas when it is Redis
+	if err != nil {
+		log.Fatalf("Initializing lockout store error: %v", err)
+	}
+
+	avatars := worker.NewAvatarWorker(repos.Photos, store, 100) //Background generation of avatar variants
+	avatars.Start(2)
+	avatars.RequeuePending()
+
+	//Background deletion of expired tokens, TOKEN_PURGE_INTERVAL is a duration like 1h
+	worker.NewTokenPurger(repos.Tokens, config.PositiveDuration("TOKEN_PURGE_INTERVAL", time.Hour)).Start()
 
-	r := router.InitRoutes(db)
+	r := router.InitRoutes(repos, store, avatars, mail, lockout.NewGuard(lockouts))
 	r.Run(":" + os.Getenv("PORT"))
 }
@@ -0,0 +1,43 @@
+package middlewares
+
+import (
+	"errors"
+	"log"
+
+	"github.com/gin-gonic/gin"
+	"task-vix-btpns/app/apperror"
+	"task-vix-btpns/app/response"
+	"task-vix-btpns/database"
+	"task-vix-btpns/repository"
+)
+
+//function to write the last error added by handlers with c.Error, must be used before every other middleware
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		err := ToAppError(c.Errors.Last().Err)
+		if err.Code == apperror.CodeInternal {
+			log.Printf("%s %s error: %v", c.Request.Method, c.Request.URL.Path, err)
+		}
+		response.Error(c, err)
+	}
+}
+
+//function to convert repository and database errors into application error
+func ToAppError(err error) *apperror.Error {
+	var unique *database.UniqueViolationError
+	var foreign *database.ForeignKeyViolationError
+
+	if errors.Is(err, repository.ErrNotFound) {
+		return apperror.NotFound("data.not_found")
+	} else if errors.As(err, &unique) {
+		return apperror.Conflict("data.exists", apperror.FieldError{Field: unique.Column, Code: "unique", Message: "data.exists"})
+	} else if errors.As(err, &foreign) {
+		return apperror.Wrap(apperror.CodeValidation, "data.related", err)
+	}
+	return apperror.As(err)
+}
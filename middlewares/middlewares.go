@@ -1,25 +1,113 @@
 package middlewares
 
 import (
-	"strings"
+	"errors"
 
 	"github.com/gin-gonic/gin"
+	"task-vix-btpns/app/apperror"
 	"task-vix-btpns/app/auth"
+	"task-vix-btpns/app/rbac"
+	"task-vix-btpns/models"
+	"task-vix-btpns/repository"
 )
 
 //function to protect routes
-func AuthMiddleware() gin.HandlerFunc {
+func AuthMiddleware(users repository.UserRepository, tokens repository.TokenRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		tokenString := c.GetHeader("Authorization") //Get bearer token
-		if tokenString == "" {
-			c.JSON(401, gin.H{"error": "Token not found"})
+		tokenString, err := auth.BearerToken(c.GetHeader("Authorization")) //Get bearer token
+		if err != nil {
+			c.Error(apperror.Unauthorized("auth.token_missing"))
+			c.Abort()
+			return
+		}
+
+		claims, err := auth.ParseToken(tokenString) //Validate token
+		if errors.Is(err, auth.ErrTokenExpired) {
+			c.Error(apperror.Wrap(apperror.CodeUnauthorized, "auth.token_expired", err))
+			c.Abort()
+			return
+		} else if err != nil {
+			c.Error(apperror.Wrap(apperror.CodeUnauthorized, "auth.token_invalid", err))
+			c.Abort()
+			return
+		}
+
+		revoked, err := tokens.IsAccessTokenRevoked(claims.Id) //Check if token has been revoked
+		if err != nil || revoked {
+			c.Error(apperror.Unauthorized("auth.token_revoked"))
 			c.Abort()
 			return
 		}
 
-		err := auth.ValidateToken(strings.Split(tokenString, "Bearer ")[1]) //Validate token
+		user, err := users.FindByID(claims.Subject) //Get user from token subject
 		if err != nil {
-			c.JSON(401, gin.H{"error": err.Error()})
+			c.Error(apperror.Unauthorized("auth.user_not_found"))
+			c.Abort()
+			return
+		}
+		if user.PasswordChangedAt != nil && claims.IssuedAt < user.PasswordChangedAt.Unix() { //Token was issued with old password
+			c.Error(apperror.Unauthorized("auth.token_revoked"))
+			c.Abort()
+			return
+		}
+		if user.IsSuspended() {
+			c.Error(apperror.Forbidden("auth.suspended"))
+			c.Abort()
+			return
+		}
+
+		c.Set("claims", claims) //Share claims and user with next handlers
+		c.Set("user", user)
+		c.Next()
+	}
+}
+
+//function to get user authenticated by AuthMiddleware
+func CurrentUser(c *gin.Context) models.User {
+	return c.MustGet("user").(models.User)
+}
+
+//function to get claims authenticated by AuthMiddleware
+func CurrentClaims(c *gin.Context) *auth.ClaimJWT {
+	return c.MustGet("claims").(*auth.ClaimJWT)
+}
+
+//function to allow only users who have verified their email, must be used after AuthMiddleware
+func RequireVerifiedEmail() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := CurrentUser(c)
+		if !user.IsVerified() {
+			c.Error(apperror.Forbidden("email.unverified"))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+//function to allow only users holding one of the roles, must be used after AuthMiddleware
+//
+//Role is read from the user loaded by AuthMiddleware, role in token may be outdated
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := CurrentUser(c) //Get user from AuthMiddleware
+		for _, role := range roles {
+			if user.Role == role {
+				c.Next()
+				return
+			}
+		}
+		c.Error(apperror.Forbidden("auth.no_access"))
+		c.Abort()
+	}
+}
+
+//function to allow only users whose current role is granted the permission, must be used after AuthMiddleware
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := CurrentUser(c) //Get user from AuthMiddleware
+		if !rbac.Can(user.Role, permission) {
+			c.Error(apperror.Forbidden("auth.no_access"))
 			c.Abort()
 			return
 		}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"task-vix-btpns/database"
+)
+
+const migrateUsage = `Usage: migrate <command>
+
+Commands:
+  status          list migrations and whether they are applied
+  up              apply every pending migration
+  down [N]        roll back the last N migrations, default 1
+  to VERSION      migrate up or down to VERSION, 0 rolls back everything
+  force VERSION   mark VERSION as applied after fixing a dirty migration by hand`
+
+//Function to run migrate subcommand, returns exit code
+func runMigrate(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	db := database.OpenDB()
+	defer db.Close()
+
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "status":
+		list, err := migrator.Status(ctx)
+		if err != nil {
+			break
+		}
+		for _, status := range list {
+			state := "pending"
+			if status.Dirty {
+				state = "dirty"
+			} else if status.Applied {
+				state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d %-40s %s\n", status.Version, status.Name, state)
+		}
+	case "up":
+		err = migrator.Up(ctx)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				fmt.Fprintln(os.Stderr, "N must be a positive number")
+				return 2
+			}
+		}
+		err = migrator.Down(ctx, steps)
+	case "to", "force":
+		if len(args) < 2 {
+			fmt.Fprintln(os.Stderr, migrateUsage)
+			return 2
+		}
+		version, parseErr := strconv.ParseInt(args[1], 10, 64)
+		if parseErr != nil {
+			fmt.Fprintln(os.Stderr, "VERSION must be a number")
+			return 2
+		}
+		if args[0] == "to" {
+			err = migrator.To(ctx, version)
+		} else {
+			err = migrator.Force(ctx, version)
+		}
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -1,36 +1,77 @@
 package models
 
 import (
-	"errors"
 	"html"
+	"strconv"
 	"strings"
 	"task-vix-btpns/app"
+	"task-vix-btpns/app/rbac"
 	"task-vix-btpns/helpers/hash"
+	"task-vix-btpns/helpers/pagination"
+	"task-vix-btpns/helpers/validation"
 	"time"
 
-	"github.com/badoux/checkmail"
 	"github.com/google/uuid"
 )
 
 type User struct {
-	ID        string    `gorm:"primary_key; unique" json:"id"`
-	Username  string    `gorm:"size:255;not null;" json:"username"`
-	Email     string    `gorm:"size:255;not null; unique" json:"email"`
-	Password  string    `gorm:"size:255;not null;" json:"password"`
-	Photos    Photo     `gorm:"constraint:OnUpdate:CASCADE, OnDelete:SET NULL;" json:"photos"`
-	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
-	UpdatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+	ID          string     `gorm:"primary_key; unique" json:"id"`
+	Username    string     `gorm:"size:255;not null;" json:"username" register:"required,max=255" update:"required,max=255"`
+	Email       string     `gorm:"size:255;not null; unique" json:"email" register:"required,email,max=255" login:"required,email"`
+	Password    string     `gorm:"size:255;not null;" json:"password" register:"required" login:"required"`
+	Role        string     `gorm:"size:50;not null;default:'user'" json:"-"`
+	SuspendedAt *time.Time `json:"-"`
+	Photos      []Photo    `gorm:"foreignkey:UserID" json:"photos,omitempty"`
+	CreatedAt   time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt   time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+
+	PasswordChangedAt *time.Time `json:"-"` //access tokens issued before it are refused
+
+	EmailVerifiedAt    *time.Time `json:"-"`
+	VerificationSentAt *time.Time `json:"-"` //last time verification link was mailed
+	ResetSentAt        *time.Time `json:"-"` //last time password reset link was mailed
+
+	TOTPSecret    string     `gorm:"size:64;not null;default:''" json:"-"` //set on setup, used once enabled
+	TOTPEnabledAt *time.Time `json:"-"`
+	TOTPLastStep  int64      `gorm:"not null;default:0" json:"-"` //time step of last accepted code, a code can't be used twice
 }
 
 type Photo struct {
-	ID       int       `gorm:"primary_key;auto_increment" json:"id"`
-	Title    string    `gorm:"size:255;not null" json:"title"`
-	Caption  string    `gorm:"size:255;not null" json:"caption"`
-	PhotoUrl string    `gorm:"size:255;not null;" json:"photo_url"`
-	UserID   string    `gorm:"not null" json:"user_id"`
-	Owner    app.Owner `gorm:"owner"`
+	ID              int            `gorm:"primary_key;auto_increment" json:"id"`
+	Title           string         `gorm:"size:255;not null" json:"title" upload:"required,max=255" change:"required,max=255"`
+	Caption         string         `gorm:"size:255;not null" json:"caption" upload:"required,max=255" change:"required,max=255"`
+	PhotoUrl        string         `gorm:"size:255;not null;" json:"photo_url" upload:"omitempty,max=255,scheme=http https" change:"required,max=255,scheme=http https"`
+	StorageKey      string         `gorm:"size:255" json:"-"`
+	MetadataRemoved string         `gorm:"size:255" json:"metadata_removed,omitempty"` //set by server from stored image only
+	VariantStatus   string         `gorm:"size:20" json:"variant_status,omitempty"`    //set by server only
+	Variants        []PhotoVariant `gorm:"foreignkey:PhotoID" json:"variants,omitempty"`
+	UserID          string         `gorm:"not null" json:"user_id"`
+	IsProfile       bool           `gorm:"not null;default:false" json:"is_profile"`
+	Owner           app.Owner      `gorm:"owner" json:"owner"`
+	CreatedAt       time.Time      `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt       time.Time      `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
 }
 
+type PhotoVariant struct {
+	ID         int    `gorm:"primary_key;auto_increment" json:"-"`
+	PhotoID    int    `gorm:"not null;index" json:"-"`
+	Size       int    `gorm:"not null" json:"size"`
+	Format     string `gorm:"size:20;not null" json:"format"`
+	Url        string `gorm:"size:255;not null" json:"url"`
+	StorageKey string `gorm:"size:255;not null" json:"-"`
+}
+
+const (
+	VariantPending = "pending" //variants are waiting for background worker
+	VariantReady   = "ready"   //every variant has been generated
+	VariantFailed  = "failed"  //image couldn't be processed
+)
+
+const (
+	RoleUser  = rbac.RoleUser  //default role for registered user
+	RoleAdmin = rbac.RoleAdmin //role allowed to manage other users
+)
+
 // USER METHODS
 
 //Inisialize user data
@@ -40,6 +81,26 @@ func (u *User) Init() {
 	u.Email = html.EscapeString(strings.TrimSpace(u.Email))
 }
 
+//Check if user holds admin role
+func (u *User) IsAdmin() bool {
+	return u.Role == RoleAdmin
+}
+
+//Check if user has been suspended by admin
+func (u *User) IsSuspended() bool {
+	return u.SuspendedAt != nil
+}
+
+//Check if user has confirmed own email
+func (u *User) IsVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+//Check if user has to enter TOTP code on login
+func (u *User) HasTwoFactor() bool {
+	return u.TOTPEnabledAt != nil
+}
+
 // Change password to hashed password
 func (u *User) HashPassword() error {
 	hashedPassword, err := hash.HashPassword(u.Password)
@@ -50,6 +111,11 @@ func (u *User) HashPassword() error {
 	return nil
 }
 
+//Check if password hash was made with outdated algorithm or parameters
+func (u *User) NeedsRehash() bool {
+	return hash.NeedsRehash(u.Password)
+}
+
 // Check password
 func (u *User) CheckPassword(providedPassword string) error {
 	err := hash.CheckPasswordHash(u.Password, providedPassword)
@@ -59,59 +125,9 @@ func (u *User) CheckPassword(providedPassword string) error {
 	return nil
 }
 
-//Validate user data
+//Validate user data, action is one of register, login or update
 func (u *User) Validate(action string) error {
-	switch strings.ToLower(action) { //Convert to lowercase
-
-		case "login": //Login case
-			if u.Email == "" {
-				return errors.New("Email is required")
-			}
-			if u.Password == "" {
-				return errors.New("Password is required")
-			}
-			if err := checkmail.ValidateFormat(u.Email); err != nil {
-				return errors.New("Email is invalid")
-			}
-			return nil
-
-		case "register": //Register case
-			if u.ID == "" {
-				return errors.New("ID is required")
-			} else if u.Email == "" {
-				return errors.New("Email is required")
-			} else if err := checkmail.ValidateFormat(u.Email); err != nil {
-				return errors.New("Email is invalid")
-			} else if u.Username == "" {
-				return errors.New("Username is required")
-			} else if u.Password == "" {
-				return errors.New("Password is required")
-			} else if len(u.Password) < 8 {
-				return errors.New("Password must be at least 8 characters")
-			}
-
-			return nil
-
-		case "update": //Update case
-			if u.ID == "" {
-				return errors.New("ID is required")
-			} else if u.Email == "" {
-				return errors.New("Email is required")
-			} else if err := checkmail.ValidateFormat(u.Email); err != nil {
-				return errors.New("invalid email")
-			} else if u.Username == "" {
-				return errors.New("Username is required")
-			} else if u.Password == "" {
-				return errors.New("Password is required")
-			} else if len(u.Password) < 8 {
-				return errors.New("Password must be at least 8 characters")
-			}
-
-			return nil
-
-		default:
-			return nil
-	}
+	return validation.Struct(action, u)
 }
 
 //PHOTO METHODS
@@ -122,30 +138,29 @@ func (p *Photo) Init() {
 	p.PhotoUrl = html.EscapeString(strings.TrimSpace(p.PhotoUrl))
 }
 
-//Function to validate Photo data
+//Function to validate Photo data, action is one of upload or change
 func (p *Photo) Validate(action string) error {
-	switch strings.ToLower(action) { //Convert to lowercase
-		case "upload": // Create/Upload case
-			if p.Title == "" {
-				return errors.New("Title is required")
-			} else if p.Caption == "" {
-				return errors.New("Caption is required")
-			} else if p.UserID == "" {
-				return errors.New("UserID is required")
-			}
-			return nil
-
-		case "change": //Change case
-			if p.Title == "" {
-				return errors.New("Title is required")
-			} else if p.Caption == "" {
-				return errors.New("Caption is required")
-			} else if p.PhotoUrl == "" {
-				return errors.New("PhotoUrl is required")
-			}
-			return nil
-
-		default:
-			return nil
+	return validation.Struct(action, p)
+}
+
+//Function to get url of avatar variant, original url is used when variant doesn't exist
+func (p *Photo) VariantUrl(size int, format string) string {
+	for _, v := range p.Variants {
+		if v.Size == size && v.Format == format {
+			return v.Url
+		}
+	}
+	return p.PhotoUrl
+}
+
+//Function to get value of sort field, used to build pagination cursor
+func (p *Photo) SortValue(field string) string {
+	switch field {
+	case "created_at":
+		return pagination.TimeValue(p.CreatedAt)
+	case "title":
+		return p.Title
+	default:
+		return strconv.Itoa(p.ID)
 	}
 }
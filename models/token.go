@@ -0,0 +1,96 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RefreshToken struct {
+	ID         string     `gorm:"primary_key; unique" json:"id"`
+	UserID     string     `gorm:"size:255;not null;index" json:"user_id"`
+	FamilyID   string     `gorm:"size:255;not null;index" json:"family_id"`
+	TokenHash  string     `gorm:"size:64;not null;unique" json:"-"`
+	ReplacedBy string     `gorm:"size:255" json:"replaced_by"`
+	ExpiresAt  time.Time  `gorm:"not null;index" json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+type RevokedToken struct {
+	JTI       string    `gorm:"primary_key; size:255" json:"jti"`
+	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"`
+	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+type PasswordResetToken struct {
+	ID        string     `gorm:"primary_key; unique" json:"id"`
+	UserID    string     `gorm:"size:255;not null;index" json:"user_id"`
+	TokenHash string     `gorm:"size:64;not null;unique" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+type RecoveryCode struct {
+	ID        string     `gorm:"primary_key; unique" json:"id"`
+	UserID    string     `gorm:"size:255;not null;index" json:"user_id"`
+	CodeHash  string     `gorm:"size:64;not null" json:"-"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+type PasswordHistory struct {
+	ID           int       `gorm:"primary_key;auto_increment" json:"id"`
+	UserID       string    `gorm:"size:255;not null;index" json:"user_id"`
+	PasswordHash string    `gorm:"size:255;not null" json:"-"`
+	CreatedAt    time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// REFRESH TOKEN METHODS
+
+//Initialize refresh token data, family is inherited when token is rotated
+func (t *RefreshToken) Init(userID string, familyID string, tokenHash string, expiresAt time.Time) {
+	t.ID = uuid.New().String()
+	t.UserID = userID
+	t.FamilyID = familyID
+	if t.FamilyID == "" {
+		t.FamilyID = uuid.New().String() //New login starts new family
+	}
+	t.TokenHash = tokenHash
+	t.ExpiresAt = expiresAt
+}
+
+//Check if refresh token has been revoked or rotated
+func (t *RefreshToken) IsRevoked() bool {
+	return t.RevokedAt != nil
+}
+
+//Check if refresh token has expired
+func (t *RefreshToken) IsExpired() bool {
+	return t.ExpiresAt.Before(time.Now())
+}
+
+// PASSWORD RESET TOKEN METHODS
+
+//Initialize password reset token data
+func (t *PasswordResetToken) Init(userID string, tokenHash string, expiresAt time.Time) {
+	t.ID = uuid.New().String()
+	t.UserID = userID
+	t.TokenHash = tokenHash
+	t.ExpiresAt = expiresAt
+}
+
+//Check if reset token can still be used
+func (t *PasswordResetToken) IsUsable() bool {
+	return t.UsedAt == nil && t.ExpiresAt.After(time.Now())
+}
+
+// RECOVERY CODE METHODS
+
+//Initialize recovery code data
+func (r *RecoveryCode) Init(userID string, codeHash string) {
+	r.ID = uuid.New().String()
+	r.UserID = userID
+	r.CodeHash = codeHash
+}
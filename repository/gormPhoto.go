@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"task-vix-btpns/app"
+	"task-vix-btpns/helpers/pagination"
+	"task-vix-btpns/models"
+
+	"github.com/jinzhu/gorm"
+)
+
+type gormPhotoRepository struct {
+	db *gorm.DB
+}
+
+//Function to query photos with their variants, every photo is read through it
+func (r *gormPhotoRepository) query() *gorm.DB {
+	return r.db.Model(&models.Photo{}).Preload("Variants")
+}
+
+//Function to apply filter to photos query
+func (r *gormPhotoRepository) filtered(query *gorm.DB, filter PhotoFilter) *gorm.DB {
+	if filter.UserID != "" {
+		query = query.Where("photos.user_id = ?", filter.UserID)
+	}
+	if filter.Title != "" {
+		query = query.Where("photos.title LIKE ? ESCAPE '!'", "%"+pagination.EscapeLike(filter.Title)+"%")
+	}
+	if filter.CreatedFrom != nil {
+		query = query.Where("photos.created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		query = query.Where("photos.created_at <= ?", *filter.CreatedTo)
+	}
+	return query
+}
+
+//Function to find photos and fill their owners, query count doesn't depend on number of photos
+func (r *gormPhotoRepository) find(query *gorm.DB) ([]models.Photo, error) {
+	photos := []models.Photo{}
+	if err := query.Find(&photos).Error; err != nil {
+		return photos, gormError(err)
+	}
+	return photos, r.loadOwners(photos)
+}
+
+//Function to find first photo of query and fill its owner
+func (r *gormPhotoRepository) first(query *gorm.DB) (models.Photo, error) {
+	var photo models.Photo
+	if err := query.First(&photo).Error; err != nil {
+		return photo, gormError(err)
+	}
+	photos := []models.Photo{photo}
+	err := r.loadOwners(photos)
+	return photos[0], err
+}
+
+//Function to fill owner of every photo with a single batched query
+func (r *gormPhotoRepository) loadOwners(photos []models.Photo) error {
+	if len(photos) == 0 {
+		return nil
+	}
+
+	ids := []string{}
+	seen := map[string]bool{}
+	for _, photo := range photos {
+		if !seen[photo.UserID] {
+			seen[photo.UserID] = true
+			ids = append(ids, photo.UserID)
+		}
+	}
+
+	users := []models.User{}
+	if err := r.db.Select("id, username, email").Where("id IN (?)", ids).Find(&users).Error; err != nil {
+		return gormError(err)
+	}
+	owners := make(map[string]app.Owner, len(users))
+	for _, user := range users {
+		owners[user.ID] = app.Owner{ID: user.ID, Username: user.Username, Email: user.Email}
+	}
+
+	for i := range photos {
+		photos[i].Owner = owners[photos[i].UserID]
+	}
+	return nil
+}
+
+func (r *gormPhotoRepository) FindByID(id int) (models.Photo, error) {
+	return r.first(r.query().Where("photos.id = ?", id))
+}
+
+func (r *gormPhotoRepository) List(filter PhotoFilter, page pagination.Params) ([]models.Photo, error) {
+	return r.find(page.Apply(r.filtered(r.query(), filter), "photos"))
+}
+
+func (r *gormPhotoRepository) Count(filter PhotoFilter) (int, error) {
+	total := 0
+	err := r.filtered(r.db.Model(&models.Photo{}), filter).Count(&total).Error
+	return total, gormError(err)
+}
+
+func (r *gormPhotoRepository) ListByUser(userID string) ([]models.Photo, error) {
+	return r.find(r.query().Where("photos.user_id = ?", userID))
+}
+
+func (r *gormPhotoRepository) FindProfile(userID string) (models.Photo, error) {
+	return r.first(r.query().Where("photos.user_id = ? AND photos.is_profile = ?", userID, true))
+}
+
+func (r *gormPhotoRepository) FindNewest(userID string) (models.Photo, error) {
+	return r.first(r.query().Where("photos.user_id = ?", userID).Order("photos.created_at desc").Order("photos.id desc"))
+}
+
+//Variants are saved by SaveVariants only, never along with photo
+func (r *gormPhotoRepository) Create(photo *models.Photo) error {
+	return gormError(r.db.Set("gorm:save_associations", false).Create(photo).Error)
+}
+
+func (r *gormPhotoRepository) Update(photo *models.Photo) error {
+	return gormError(r.db.Set("gorm:save_associations", false).Model(&models.Photo{ID: photo.ID}).Updates(photo).Error)
+}
+
+func (r *gormPhotoRepository) Delete(id int) error {
+	return gormError(r.db.Where("id = ?", id).Delete(&models.Photo{}).Error) //Variants are removed by foreign key cascade
+}
+
+func (r *gormPhotoRepository) SetProfile(photo models.Photo) error {
+	tx := r.db.Begin()
+	err := tx.Model(&models.Photo{}).Where("user_id = ? AND id <> ?", photo.UserID, photo.ID).Update("is_profile", false).Error
+	if err == nil {
+		err = tx.Model(&models.Photo{}).Where("id = ?", photo.ID).Update("is_profile", true).Error
+	}
+	if err != nil {
+		tx.Rollback()
+		return gormError(err)
+	}
+	return gormError(tx.Commit().Error)
+}
+
+func (r *gormPhotoRepository) ClearStorage(id int) error {
+	return gormError(r.db.Model(&models.Photo{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"storage_key":      "",
+		"metadata_removed": "",
+		"variant_status":   "",
+	}).Error)
+}
+
+func (r *gormPhotoRepository) DeleteVariants(id int) error {
+	return gormError(r.db.Where("photo_id = ?", id).Delete(&models.PhotoVariant{}).Error)
+}
+
+func (r *gormPhotoRepository) ListPending() ([]models.Photo, error) {
+	photos := []models.Photo{}
+	err := r.db.Where("variant_status = ?", models.VariantPending).Find(&photos).Error
+	return photos, gormError(err)
+}
+
+func (r *gormPhotoRepository) SetVariantStatus(id int, status string) error {
+	return gormError(r.db.Model(&models.Photo{}).Where("id = ?", id).Update("variant_status", status).Error)
+}
+
+func (r *gormPhotoRepository) SaveVariants(photo models.Photo, variants []models.PhotoVariant) (bool, error) {
+	tx := r.db.Begin()
+	var current models.Photo
+	if err := tx.Where("id = ?", photo.ID).First(&current).Error; err != nil || current.StorageKey != photo.StorageKey {
+		tx.Rollback()
+		return false, nil
+	}
+	err := tx.Where("photo_id = ?", photo.ID).Delete(&models.PhotoVariant{}).Error
+	for i := range variants {
+		if err == nil {
+			err = tx.Create(&variants[i]).Error
+		}
+	}
+	if err == nil {
+		err = tx.Model(&current).Update("variant_status", models.VariantReady).Error
+	}
+	if err != nil {
+		tx.Rollback()
+		return false, gormError(err)
+	}
+	return true, gormError(tx.Commit().Error)
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+
+	"task-vix-btpns/database"
+	"task-vix-btpns/helpers/pagination"
+	"task-vix-btpns/models"
+
+	"github.com/jinzhu/gorm"
+)
+
+//Fields photos can be sorted by in tests
+var testSortFields = map[string]pagination.Field{
+	"id":         {Column: "id", Kind: pagination.KindInt},
+	"created_at": {Column: "created_at", Kind: pagination.KindTime},
+}
+
+//Function to open migrated in-memory SQLite database with photos of several owners, every photo has variants
+func seedPhotos(t testing.TB, owners int, photos int) *gorm.DB {
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("DB_NAME", ":memory:")
+	db := database.ConnectDB()
+	t.Cleanup(func() { db.Close() })
+
+	repos := NewGormRepositories(db)
+	for i := 0; i < owners; i++ {
+		user := models.User{Username: fmt.Sprintf("user%d", i), Email: fmt.Sprintf("user%d@mail.com", i), Password: "hash"}
+		user.Init()
+		if err := repos.Users.Create(&user); err != nil {
+			t.Fatalf("Creating user error: %v", err)
+		}
+		for j := i; j < photos; j += owners {
+			photo := models.Photo{Title: "photo", Caption: "caption", PhotoUrl: "http://mail.com/photo.jpg", UserID: user.ID}
+			if err := repos.Photos.Create(&photo); err != nil {
+				t.Fatalf("Creating photo error: %v", err)
+			}
+			variants := []models.PhotoVariant{
+				{PhotoID: photo.ID, Size: 64, Format: "jpeg", Url: "http://mail.com/64.jpg", StorageKey: "64.jpg"},
+				{PhotoID: photo.ID, Size: 128, Format: "jpeg", Url: "http://mail.com/128.jpg", StorageKey: "128.jpg"},
+			}
+			if _, err := repos.Photos.SaveVariants(photo, variants); err != nil {
+				t.Fatalf("Saving variants error: %v", err)
+			}
+		}
+	}
+	return db
+}
+
+//Function to count queries run through db, count is read and reset by caller
+func countQueries(db *gorm.DB) *int {
+	count := 0
+	db.Callback().Query().After("gorm:query").Register("test:count_queries", func(scope *gorm.Scope) {
+		count++
+	})
+	return &count
+}
+
+func TestListQueryCountDoesNotGrowWithPageSize(t *testing.T) {
+	db := seedPhotos(t, 10, 100)
+	photos := &gormPhotoRepository{db: db}
+	queries := countQueries(db)
+
+	expected := -1
+	for _, limit := range []int{1, 5, 20, 50, 100} {
+		page, err := pagination.Parse(strconv.Itoa(limit), "", "", testSortFields, "-created_at")
+		if err != nil {
+			t.Fatalf("Parsing page error: %v", err)
+		}
+
+		*queries = 0
+		list, err := photos.List(PhotoFilter{}, page)
+		if err != nil {
+			t.Fatalf("Listing %d photos error: %v", limit, err)
+		}
+
+		//Extra row tells whether there is a next page
+		if len(list) != limit+1 && len(list) != 100 {
+			t.Fatalf("Listing %d photos returned %d", limit, len(list))
+		}
+		for _, photo := range list {
+			if photo.Owner.ID != photo.UserID || len(photo.Variants) != 2 {
+				t.Fatalf("Photo %d is listed without its owner or variants", photo.ID)
+			}
+		}
+
+		if expected == -1 {
+			expected = *queries
+		} else if *queries != expected {
+			t.Fatalf("Listing %d photos ran %d queries, page of 1 ran %d", limit, *queries, expected)
+		}
+	}
+
+	//Photos, their variants and their owners are read in one query each
+	if expected != 3 {
+		t.Fatalf("Listing photos ran %d queries, want 3", expected)
+	}
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"task-vix-btpns/models"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+type gormTokenRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormTokenRepository) CreateRefreshToken(token *models.RefreshToken) error {
+	return gormError(r.db.Create(token).Error)
+}
+
+func (r *gormTokenRepository) FindRefreshToken(tokenHash string) (models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := r.db.Where("token_hash = ?", tokenHash).First(&token).Error
+	return token, gormError(err)
+}
+
+func (r *gormTokenRepository) RotateRefreshToken(old models.RefreshToken, next *models.RefreshToken) (bool, error) {
+	tx := r.db.Begin()
+	//Only one of concurrent rotations of the same token revokes it, the others see it as reused
+	result := tx.Model(&models.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", old.ID).
+		Updates(map[string]interface{}{
+			"revoked_at":  time.Now(),
+			"replaced_by": next.ID,
+		})
+	if result.Error != nil || result.RowsAffected != 1 {
+		tx.Rollback()
+		return false, gormError(result.Error)
+	}
+	if err := tx.Create(next).Error; err != nil {
+		tx.Rollback()
+		return false, gormError(err)
+	}
+	return true, gormError(tx.Commit().Error)
+}
+
+func (r *gormTokenRepository) RevokeFamily(familyID string) error {
+	return gormError(r.db.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error)
+}
+
+func (r *gormTokenRepository) RevokeUserTokens(userID string) error {
+	return gormError(r.db.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error)
+}
+
+func (r *gormTokenRepository) RevokeAccessToken(token *models.RevokedToken) error {
+	return gormError(r.db.Create(token).Error)
+}
+
+func (r *gormTokenRepository) IsAccessTokenRevoked(jti string) (bool, error) {
+	err := r.db.Where("jti = ?", jti).First(&models.RevokedToken{}).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return false, nil
+	}
+	return err == nil, gormError(err)
+}
+
+func (r *gormTokenRepository) PurgeExpired(before time.Time) (int64, error) {
+	refresh := r.db.Where("expires_at < ?", before).Delete(&models.RefreshToken{})
+	if refresh.Error != nil {
+		return 0, gormError(refresh.Error)
+	}
+	revoked := r.db.Where("expires_at < ?", before).Delete(&models.RevokedToken{})
+	return refresh.RowsAffected + revoked.RowsAffected, gormError(revoked.Error)
+}
+
+func (r *gormTokenRepository) CreateResetToken(token *models.PasswordResetToken) error {
+	tx := r.db.Begin()
+	err := tx.Model(&models.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", token.UserID).
+		Update("used_at", time.Now()).Error
+	if err == nil {
+		err = tx.Create(token).Error
+	}
+	if err != nil {
+		tx.Rollback()
+		return gormError(err)
+	}
+	return gormError(tx.Commit().Error)
+}
+
+func (r *gormTokenRepository) FindResetToken(tokenHash string) (models.PasswordResetToken, error) {
+	var token models.PasswordResetToken
+	err := r.db.Where("token_hash = ?", tokenHash).First(&token).Error
+	return token, gormError(err)
+}
+
+func (r *gormTokenRepository) UseResetToken(id string) (bool, error) {
+	result := r.db.Model(&models.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	return result.RowsAffected == 1, gormError(result.Error)
+}
@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"task-vix-btpns/database"
+	"task-vix-btpns/models"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+//Function to create repositories backed by database
+func NewGormRepositories(db *gorm.DB) Repositories {
+	return Repositories{
+		Users:  &gormUserRepository{db: db},
+		Photos: &gormPhotoRepository{db: db},
+		Tokens: &gormTokenRepository{db: db},
+	}
+}
+
+//Function to convert GORM and driver errors into repository errors
+func gormError(err error) error {
+	if gorm.IsRecordNotFoundError(err) {
+		return ErrNotFound
+	}
+	return database.TranslateError(err)
+}
+
+type gormUserRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormUserRepository) FindByID(id string) (models.User, error) {
+	var user models.User
+	err := r.db.Where("id = ?", id).First(&user).Error
+	return user, gormError(err)
+}
+
+func (r *gormUserRepository) FindByEmail(email string) (models.User, error) {
+	var user models.User
+	err := r.db.Where("email = ?", email).First(&user).Error
+	return user, gormError(err)
+}
+
+func (r *gormUserRepository) List() ([]models.User, error) {
+	users := []models.User{}
+	err := r.db.Order("created_at desc").Find(&users).Error
+	return users, gormError(err)
+}
+
+//Photos of user are never saved along with it, they have their own repository
+func (r *gormUserRepository) Create(user *models.User) error {
+	return gormError(r.db.Set("gorm:save_associations", false).Create(user).Error)
+}
+
+func (r *gormUserRepository) Update(user *models.User) error {
+	return gormError(r.db.Set("gorm:save_associations", false).Model(&models.User{ID: user.ID}).Updates(user).Error)
+}
+
+func (r *gormUserRepository) Delete(id string) error {
+	return gormError(r.db.Where("id = ?", id).Delete(&models.User{}).Error) //Photos and tokens are removed by foreign key cascade
+}
+
+func (r *gormUserRepository) SetRole(id string, role string) error {
+	return gormError(r.db.Model(&models.User{}).Where("id = ?", id).Update("role", role).Error)
+}
+
+func (r *gormUserRepository) SetSuspended(id string, suspendedAt *time.Time) error {
+	tx := r.db.Begin()
+	err := tx.Model(&models.User{}).Where("id = ?", id).Update("suspended_at", suspendedAt).Error
+	if err == nil && suspendedAt != nil {
+		err = (&gormTokenRepository{db: tx}).RevokeUserTokens(id) //Suspended user loses every session
+	}
+	if err != nil {
+		tx.Rollback()
+		return gormError(err)
+	}
+	return gormError(tx.Commit().Error)
+}
+
+func (r *gormUserRepository) RehashPassword(id string, oldHash string, newHash string) error {
+	return gormError(r.db.Model(&models.User{}).
+		Where("id = ? AND password = ?", id, oldHash).
+		UpdateColumn("password", newHash).Error)
+}
+
+func (r *gormUserRepository) PasswordHistory(userID string, limit int) ([]string, error) {
+	hashes := []string{}
+	err := r.db.Model(&models.PasswordHistory{}).Where("user_id = ?", userID).
+		Order("id desc").Limit(limit).Pluck("password_hash", &hashes).Error
+	return hashes, gormError(err)
+}
+
+func (r *gormUserRepository) AddPasswordHistory(userID string, passwordHash string, keep int) error {
+	if keep <= 0 {
+		return gormError(r.db.Where("user_id = ?", userID).Delete(&models.PasswordHistory{}).Error)
+	}
+	tx := r.db.Begin()
+	err := tx.Create(&models.PasswordHistory{UserID: userID, PasswordHash: passwordHash}).Error
+
+	//Hashes older than the oldest kept one are removed
+	ids := []int{}
+	if err == nil {
+		err = tx.Model(&models.PasswordHistory{}).Where("user_id = ?", userID).
+			Order("id desc").Offset(keep-1).Limit(1).Pluck("id", &ids).Error
+	}
+	if err == nil && len(ids) == 1 {
+		err = tx.Where("user_id = ? AND id < ?", userID, ids[0]).Delete(&models.PasswordHistory{}).Error
+	}
+	if err != nil {
+		tx.Rollback()
+		return gormError(err)
+	}
+	return gormError(tx.Commit().Error)
+}
+
+func (r *gormUserRepository) VerifyEmail(id string, email string) (bool, error) {
+	result := r.db.Model(&models.User{}).
+		Where("id = ? AND email = ?", id, email).
+		UpdateColumn("email_verified_at", time.Now())
+	return result.RowsAffected == 1, gormError(result.Error)
+}
+
+func (r *gormUserRepository) UnverifyEmail(id string) error {
+	return gormError(r.db.Model(&models.User{}).Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{"email_verified_at": nil, "verification_sent_at": nil}).Error)
+}
+
+func (r *gormUserRepository) ChangeEmail(id string, email string, newEmail string) (bool, error) {
+	result := r.db.Model(&models.User{}).
+		Where("id = ? AND email = ?", id, email).
+		UpdateColumns(map[string]interface{}{"email": newEmail, "email_verified_at": time.Now(), "verification_sent_at": nil})
+	return result.RowsAffected == 1, gormError(result.Error)
+}
+
+func (r *gormUserRepository) ClaimVerificationSend(id string, sentBefore time.Time) (bool, error) {
+	result := r.db.Model(&models.User{}).
+		Where("id = ? AND (verification_sent_at IS NULL OR verification_sent_at < ?)", id, sentBefore).
+		UpdateColumn("verification_sent_at", time.Now())
+	return result.RowsAffected == 1, gormError(result.Error)
+}
+
+func (r *gormUserRepository) ClaimResetSend(id string, sentBefore time.Time) (bool, error) {
+	result := r.db.Model(&models.User{}).
+		Where("id = ? AND (reset_sent_at IS NULL OR reset_sent_at < ?)", id, sentBefore).
+		UpdateColumn("reset_sent_at", time.Now())
+	return result.RowsAffected == 1, gormError(result.Error)
+}
+
+func (r *gormUserRepository) SetTOTPSecret(id string, secret string) (bool, error) {
+	result := r.db.Model(&models.User{}).
+		Where("id = ? AND totp_enabled_at IS NULL", id).
+		UpdateColumn("totp_secret", secret)
+	return result.RowsAffected == 1, gormError(result.Error)
+}
+
+func (r *gormUserRepository) EnableTOTP(id string, step int64, codes []models.RecoveryCode) (bool, error) {
+	tx := r.db.Begin()
+	result := tx.Model(&models.User{}).
+		Where("id = ? AND totp_enabled_at IS NULL", id).
+		UpdateColumns(map[string]interface{}{"totp_enabled_at": time.Now(), "totp_last_step": step})
+	err := result.Error
+	if err == nil && result.RowsAffected == 1 {
+		err = tx.Where("user_id = ?", id).Delete(&models.RecoveryCode{}).Error
+		for i := 0; err == nil && i < len(codes); i++ {
+			err = tx.Create(&codes[i]).Error
+		}
+	}
+	if err != nil || result.RowsAffected != 1 {
+		tx.Rollback()
+		return false, gormError(err)
+	}
+	return true, gormError(tx.Commit().Error)
+}
+
+func (r *gormUserRepository) DisableTOTP(id string) error {
+	tx := r.db.Begin()
+	err := tx.Model(&models.User{}).Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{"totp_secret": "", "totp_enabled_at": nil, "totp_last_step": 0}).Error
+	if err == nil {
+		err = tx.Where("user_id = ?", id).Delete(&models.RecoveryCode{}).Error
+	}
+	if err != nil {
+		tx.Rollback()
+		return gormError(err)
+	}
+	return gormError(tx.Commit().Error)
+}
+
+func (r *gormUserRepository) UseTOTPStep(id string, step int64) (bool, error) {
+	result := r.db.Model(&models.User{}).
+		Where("id = ? AND totp_last_step < ?", id, step).
+		UpdateColumn("totp_last_step", step)
+	return result.RowsAffected == 1, gormError(result.Error)
+}
+
+func (r *gormUserRepository) UseRecoveryCode(userID string, codeHash string) (bool, error) {
+	result := r.db.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	return result.RowsAffected == 1, gormError(result.Error)
+}
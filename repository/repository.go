@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"errors"
+	"task-vix-btpns/helpers/pagination"
+	"task-vix-btpns/models"
+	"time"
+)
+
+//ErrNotFound is returned when the requested row doesn't exist
+var ErrNotFound = errors.New("Data not found")
+
+//Storage of users
+type UserRepository interface {
+	FindByID(id string) (models.User, error)
+	FindByEmail(email string) (models.User, error)
+	List() ([]models.User, error) //newest user first
+	Create(user *models.User) error
+	Update(user *models.User) error //save non-empty fields of user matched by its ID
+	Delete(id string) error         //photos and tokens of user are deleted too
+	SetRole(id string, role string) error
+	SetSuspended(id string, suspendedAt *time.Time) error                //suspending also revokes every refresh token of user
+	VerifyEmail(id string, email string) (bool, error)                   //false when email of user is no longer the verified one
+	UnverifyEmail(id string) error                                       //changed email has to be verified again
+	ChangeEmail(id string, email string, newEmail string) (bool, error)  //set confirmed newEmail, false when email of user is no longer email
+	ClaimVerificationSend(id string, sentBefore time.Time) (bool, error) //mark link as sent now, false when last one was sent after sentBefore
+	ClaimResetSend(id string, sentBefore time.Time) (bool, error)        //same as ClaimVerificationSend for password reset links
+	RehashPassword(id string, oldHash string, newHash string) error      //replace hash only while it is still oldHash
+
+	//Password history
+	PasswordHistory(userID string, limit int) ([]string, error)            //hashes of previous passwords, newest first
+	AddPasswordHistory(userID string, passwordHash string, keep int) error //only the newest keep hashes are kept
+
+	//Two-factor authentication
+	SetTOTPSecret(id string, secret string) (bool, error)                        //false when two-factor is enabled already
+	EnableTOTP(id string, step int64, codes []models.RecoveryCode) (bool, error) //replace recovery codes, false when enabled already
+	DisableTOTP(id string) error                                                 //secret and recovery codes are removed
+	UseTOTPStep(id string, step int64) (bool, error)                             //false when a code of this or later step was used already
+	UseRecoveryCode(userID string, codeHash string) (bool, error)                //false when code doesn't exist or was used already
+}
+
+//Photos shown in list, matched by every non-empty field
+type PhotoFilter struct {
+	UserID      string
+	Title       string //part of title
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time //inclusive, date given without time is already moved to end of its day
+}
+
+//Storage of photos and their avatar variants, photos are returned with their variants and owner
+type PhotoRepository interface {
+	FindByID(id int) (models.Photo, error)
+	List(filter PhotoFilter, page pagination.Params) ([]models.Photo, error) //returns one extra photo when there is a next page
+	Count(filter PhotoFilter) (int, error)
+	ListByUser(userID string) ([]models.Photo, error)
+	FindProfile(userID string) (models.Photo, error)
+	FindNewest(userID string) (models.Photo, error)
+	Create(photo *models.Photo) error
+	Update(photo *models.Photo) error //save non-empty fields of photo matched by its ID
+	Delete(id int) error
+	SetProfile(photo models.Photo) error //unset profile flag of every other photo of the owner
+	ClearStorage(id int) error           //forget stored object, its metadata and variants once photo url no longer points to it
+	DeleteVariants(id int) error
+	ListPending() ([]models.Photo, error)
+	SetVariantStatus(id int, status string) error
+	SaveVariants(photo models.Photo, variants []models.PhotoVariant) (bool, error) //false when image was replaced meanwhile
+}
+
+//Storage of refresh tokens and revoked access tokens
+type TokenRepository interface {
+	CreateRefreshToken(token *models.RefreshToken) error
+	FindRefreshToken(tokenHash string) (models.RefreshToken, error)
+	RotateRefreshToken(old models.RefreshToken, next *models.RefreshToken) (bool, error) //revoke old and create next at once, false when old was revoked already
+	RevokeFamily(familyID string) error
+	RevokeUserTokens(userID string) error
+	RevokeAccessToken(token *models.RevokedToken) error
+	IsAccessTokenRevoked(jti string) (bool, error)
+	CreateResetToken(token *models.PasswordResetToken) error //unused reset tokens of the user can't be used anymore
+	FindResetToken(tokenHash string) (models.PasswordResetToken, error)
+	UseResetToken(id string) (bool, error)        //false when token has been used already
+	PurgeExpired(before time.Time) (int64, error) //delete refresh and revoked access tokens which expired before, returns rows deleted
+}
+
+//Every repository used by handlers
+type Repositories struct {
+	Users  UserRepository
+	Photos PhotoRepository
+	Tokens TokenRepository
+}
@@ -1,33 +1,95 @@
 package router
 
 import (
+	"log"
+	"strings"
+
 	"github.com/gin-gonic/gin"
-	"github.com/jinzhu/gorm"
+	"task-vix-btpns/app/rbac"
 	"task-vix-btpns/controllers"
+	"task-vix-btpns/helpers/config"
+	"task-vix-btpns/lockout"
+	"task-vix-btpns/mailer"
 	"task-vix-btpns/middlewares"
+	"task-vix-btpns/repository"
+	"task-vix-btpns/storage"
 )
 
-//Function to initialize routes
-func InitRoutes(db *gorm.DB) *gin.Engine {
+//Function to initialize routes, handlers are given every dependency they use
+func InitRoutes(repos repository.Repositories, store storage.Storage, avatars controllers.AvatarQueue, mail mailer.Mailer, guard *lockout.Guard) *gin.Engine {
 	router := gin.Default()
+	router.Use(middlewares.ErrorHandler()) //Write errors of every route in one response format
+
+	//Client address is taken from X-Forwarded-For only when request comes through one of TRUSTED_PROXIES,
+	//addresses or CIDRs separated by comma, none by default so that login lockout can't be dodged by the header
+	err := router.SetTrustedProxies(config.List("TRUSTED_PROXIES"))
+	if err != nil {
+		log.Fatalf("Loading trusted proxies error: %v", err)
+	}
+
+	users := controllers.NewUserController(repos, store, mail, guard)
+	photos := controllers.NewPhotoController(repos, store, avatars)
+	tokens := controllers.NewTokenController(repos)
+	passwords := controllers.NewPasswordController(repos, mail, guard)
+	verifications := controllers.NewVerificationController(repos, mail)
+	twoFactor := controllers.NewTwoFactorController(repos, guard)
+	admins := controllers.NewAdminController(repos, store, guard)
+	accounts := controllers.NewAccountController(repos, mail, guard)
+	auth := middlewares.AuthMiddleware(repos.Users, repos.Tokens)
+
+	//Serve uploaded photos when they are kept in local filesystem
+	if local, ok := store.(*storage.Local); ok && strings.HasPrefix(local.PublicURL, "/") {
+		router.Static(local.PublicURL, local.Dir)
+	}
 
-	router.Use(func(c *gin.Context) {
-		c.Set("db", db)
-	})
+	//Public keys to verify JWT token
+	router.GET("/.well-known/jwks.json", controllers.GetJWKS)
 
 	//User Routes
-	router.POST("/users/login", controllers.Login)
-	router.POST("/users/register", controllers.CreateUser)
-	router.PUT("/users/:userId", controllers.UpdateUser)
-	router.DELETE("/users/:userId", controllers.DeleteUser)
-
-	router.GET("/photos", controllers.GetPhoto)
-	//Middlewares for photo
-	authorized := router.Group("/").Use(middlewares.AuthMiddleware())
+	router.POST("/users/login", users.Login)
+	router.POST("/users/login/2fa", twoFactor.VerifyLogin)
+	router.POST("/users/register", users.CreateUser)
+	router.POST("/users/refresh", tokens.RefreshToken)
+	router.POST("/users/password/forgot", passwords.ForgotPassword)
+	router.POST("/users/password/reset", passwords.ResetPassword)
+	router.GET("/users/verify", verifications.VerifyEmail)
+	router.GET("/users/email/confirm", accounts.ConfirmEmailChange)
+
+	router.GET("/photos", photos.GetPhoto)
+	router.GET("/photos/:photoId", photos.GetPhotoByID)
+	router.GET("/users/:userId/photos", photos.GetUserPhotos)
+	//Middlewares for protected routes
+	authorized := router.Group("/").Use(auth)
+	verified := middlewares.RequireVerifiedEmail()
+	{
+		authorized.POST("/users/logout", tokens.Logout)
+		authorized.GET("/users/me", users.GetMe)
+		authorized.PATCH("/users/me", accounts.UpdateProfile)
+		authorized.POST("/users/me/password", accounts.ChangePassword)
+		authorized.POST("/users/me/email", accounts.ChangeEmail)
+		authorized.POST("/users/verify/resend", verifications.ResendVerification)
+		authorized.POST("/users/2fa/setup", twoFactor.Setup)
+		authorized.POST("/users/2fa/enable", twoFactor.Enable)
+		authorized.POST("/users/2fa/disable", twoFactor.Disable)
+		authorized.GET("/users/:userId", users.GetUser)
+		authorized.PUT("/users/:userId", users.UpdateUser)
+		authorized.DELETE("/users/:userId", users.DeleteUser)
+		authorized.POST("/photos", verified, photos.CreatePhoto)
+		authorized.PUT("/photos/:photoId", verified, photos.UpdatePhoto)
+		authorized.PUT("/photos/:photoId/profile", photos.SetProfilePhoto)
+		authorized.DELETE("/photos/:photoId", photos.DeletePhoto)
+	}
+
+	//Admin Routes
+	admin := router.Group("/admin").Use(auth, middlewares.RequireRole(rbac.RoleAdmin))
 	{
-		authorized.POST("/photos", controllers.CreatePhoto)
-		authorized.PUT("/photos/:photoId", controllers.UpdatePhoto)
-		authorized.DELETE("/photos/:photoId", controllers.DeletePhoto)
+		admin.GET("/users", middlewares.RequirePermission(rbac.ListUsers), admins.AdminGetUsers)
+		admin.POST("/users/:userId/suspend", middlewares.RequirePermission(rbac.SuspendUsers), admins.AdminSuspendUser)
+		admin.POST("/users/:userId/unsuspend", middlewares.RequirePermission(rbac.SuspendUsers), admins.AdminUnsuspendUser)
+		admin.POST("/users/:userId/unlock", middlewares.RequirePermission(rbac.UnlockUsers), admins.AdminUnlockUser)
+		admin.PUT("/users/:userId/role", middlewares.RequirePermission(rbac.UpdateRoles), admins.AdminUpdateRole)
+		admin.DELETE("/users/:userId", middlewares.RequirePermission(rbac.DeleteUsers), admins.AdminDeleteUser)
+		admin.DELETE("/photos/:photoId", middlewares.RequirePermission(rbac.DeletePhotos), admins.AdminDeletePhoto)
 	}
 	return router
-}
\ No newline at end of file
+}
@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"task-vix-btpns/helpers/config"
+)
+
+//Storage keeps uploaded objects and tells where they can be downloaded
+type Storage interface {
+	//Put stores object under key and returns its public url
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) (string, error)
+	//Get opens object stored under key, caller must close it
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	//Delete removes object stored under key
+	Delete(ctx context.Context, key string) error
+}
+
+//Function to create storage based on STORAGE_DRIVER, local is used by default
+func New() (Storage, error) {
+	switch strings.ToLower(os.Getenv("STORAGE_DRIVER")) {
+	case "", "local":
+		return NewLocal(config.Get("STORAGE_LOCAL_DIR", "uploads"), config.Get("STORAGE_PUBLIC_URL", "/uploads"))
+	case "s3":
+		return NewS3(S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			UseSSL:    os.Getenv("S3_USE_SSL") != "false",
+			PublicURL: os.Getenv("S3_PUBLIC_URL"),
+		})
+	default:
+		return nil, fmt.Errorf("Storage driver %s is not supported", os.Getenv("STORAGE_DRIVER"))
+	}
+}
@@ -0,0 +1,129 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"sync"
+
+	"task-vix-btpns/helpers/imaging"
+	"task-vix-btpns/models"
+	"task-vix-btpns/repository"
+	"task-vix-btpns/storage"
+)
+
+//Background worker which generates avatar variants of uploaded photos
+type AvatarWorker struct {
+	photos repository.PhotoRepository
+	store  storage.Storage
+	jobs   chan int //id of photo waiting for variants
+	wg     sync.WaitGroup
+}
+
+//Function to create avatar worker, queueSize is the number of photos that can wait
+func NewAvatarWorker(photos repository.PhotoRepository, store storage.Storage, queueSize int) *AvatarWorker {
+	return &AvatarWorker{photos: photos, store: store, jobs: make(chan int, queueSize)}
+}
+
+//Function to start workers goroutines
+func (w *AvatarWorker) Start(workers int) {
+	for i := 0; i < workers; i++ {
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			for photoID := range w.jobs {
+				if err := w.process(photoID); err != nil {
+					log.Printf("Generating variants of photo %d error: %v", photoID, err)
+					w.photos.SetVariantStatus(photoID, models.VariantFailed)
+				}
+			}
+		}()
+	}
+}
+
+//Function to stop accepting jobs and wait for running ones
+func (w *AvatarWorker) Stop() {
+	close(w.jobs)
+	w.wg.Wait()
+}
+
+//Function to queue photo, returns false when queue is full
+func (w *AvatarWorker) Enqueue(photoID int) bool {
+	select {
+	case w.jobs <- photoID:
+		return true
+	default:
+		log.Printf("Avatar queue is full, photo %d stays pending", photoID)
+		return false
+	}
+}
+
+//Function to queue photos left pending, e.g. by previous process
+func (w *AvatarWorker) RequeuePending() {
+	photos, err := w.photos.ListPending()
+	if err != nil {
+		log.Printf("Loading pending photos error: %v", err)
+		return
+	}
+	for _, photo := range photos {
+		if !w.Enqueue(photo.ID) {
+			return
+		}
+	}
+}
+
+//Function to generate every variant of a photo
+func (w *AvatarWorker) process(photoID int) error {
+	ctx := context.Background()
+
+	photo, err := w.photos.FindByID(photoID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil //photo was deleted while waiting
+		}
+		return err
+	}
+	if photo.StorageKey == "" {
+		return nil
+	}
+
+	//Read original image
+	object, err := w.store.Get(ctx, photo.StorageKey)
+	if err != nil {
+		return err
+	}
+	img, err := imaging.Decode(object)
+	object.Close()
+	if err != nil {
+		return err
+	}
+
+	//Generate and store every size in every format
+	variants := []models.PhotoVariant{}
+	for _, size := range imaging.Sizes {
+		square := imaging.Square(img, size)
+		for _, format := range imaging.Formats {
+			var buf bytes.Buffer
+			if err := imaging.Encode(&buf, square, format); err != nil {
+				return err
+			}
+			key := imaging.VariantKey(photo.StorageKey, size, format)
+			url, err := w.store.Put(ctx, key, &buf, int64(buf.Len()), imaging.ContentType(format))
+			if err != nil {
+				return err
+			}
+			variants = append(variants, models.PhotoVariant{
+				PhotoID: photo.ID, Size: size, Format: format, Url: url, StorageKey: key,
+			})
+		}
+	}
+
+	//Save variants, unless the image was replaced while they were generated
+	saved, err := w.photos.SaveVariants(photo, variants)
+	if err == nil && !saved {
+		for _, variant := range variants {
+			w.store.Delete(ctx, variant.StorageKey)
+		}
+	}
+	return err
+}
@@ -0,0 +1,58 @@
+package worker
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"task-vix-btpns/repository"
+)
+
+//Background worker which deletes expired refresh tokens and revoked access tokens, they are refused anyway once expired
+type TokenPurger struct {
+	tokens   repository.TokenRepository
+	interval time.Duration
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+//Function to create token purger which runs every interval
+func NewTokenPurger(tokens repository.TokenRepository, interval time.Duration) *TokenPurger {
+	return &TokenPurger{tokens: tokens, interval: interval, stop: make(chan struct{})}
+}
+
+//Function to start purging now and then every interval
+func (p *TokenPurger) Start() {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+		for {
+			p.Purge()
+			select {
+			case <-ticker.C:
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+}
+
+//Function to stop purging and wait for running purge
+func (p *TokenPurger) Stop() {
+	close(p.stop)
+	p.wg.Wait()
+}
+
+//Function to delete tokens which have expired
+func (p *TokenPurger) Purge() {
+	deleted, err := p.tokens.PurgeExpired(time.Now())
+	if err != nil {
+		log.Printf("Purging expired tokens error: %v", err)
+		return
+	}
+	if deleted > 0 {
+		log.Printf("Purged %d expired tokens", deleted)
+	}
+}